@@ -0,0 +1,179 @@
+//go:build with_fsnotify
+
+package globwatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// run detects changes until w is closed or ctx is done. This backend uses
+// fsnotify to trigger a change detection as soon as a file matching w's
+// pattern changes. The filesystem is still polled every interval to pick up
+// changes fsnotify did not report. fsnotify requires a directory of the
+// operating system's filesystem; for any other fs.FS run falls back to
+// polling.
+func (w *Watcher) run(ctx context.Context) {
+	root, ok := osRoot(w.fsys)
+	if !ok {
+		w.poll(ctx)
+		return
+	}
+
+	nw, err := fsnotify.NewWatcher()
+	if err != nil {
+		w.reportError(fmt.Errorf("failed to create fsnotify watcher: %w", err))
+		w.poll(ctx)
+		return
+	}
+	defer nw.Close()
+
+	n := &notifier{
+		w:    w,
+		nw:   nw,
+		root: root,
+		dirs: make(map[string]struct{}),
+	}
+
+	if err := n.addDirs("."); err != nil {
+		w.reportError(err)
+	}
+
+	// Pick up changes made while the directories were being added.
+	w.scan(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case evt := <-nw.Events:
+			if n.drain(n.handle(evt)) && w.failed(w.scan(ctx)) {
+				return
+			}
+		case err := <-nw.Errors:
+			w.reportError(err)
+		case <-ticker.C:
+			if w.failed(w.scan(ctx)) {
+				return
+			}
+			ticker.Reset(w.nextInterval())
+		case <-w.close:
+			w.closeGracefully(ctx)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// osRoot returns the directory fsys has been created for using os.DirFS.
+// It returns false if fsys has not been created by os.DirFS.
+func osRoot(fsys fs.FS) (string, bool) {
+	v := reflect.ValueOf(fsys)
+	if v.Kind() != reflect.String || v.Type().PkgPath() != "os" {
+		return "", false
+	}
+
+	return v.String(), true
+}
+
+// notifier maintains the directories watched via fsnotify.
+type notifier struct {
+	w    *Watcher
+	nw   *fsnotify.Watcher
+	root string
+	dirs map[string]struct{}
+}
+
+// addDirs adds dir and all its subdirectories that may contain files
+// matching the watcher's pattern.
+func (n *notifier) addDirs(dir string) error {
+	return fs.WalkDir(n.w.fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if p != "." && !n.w.canDescend(p) {
+			return fs.SkipDir
+		}
+
+		if err := n.nw.Add(filepath.Join(n.root, filepath.FromSlash(p))); err != nil {
+			return fmt.Errorf("failed to watch directory %s: %w", p, err)
+		}
+		n.dirs[p] = struct{}{}
+
+		return nil
+	})
+}
+
+// handle handles a single fsnotify event and reports whether it requires a
+// change detection. Events for files not matching the pattern are dropped.
+func (n *notifier) handle(evt fsnotify.Event) bool {
+	if evt.Op == fsnotify.Chmod {
+		return false
+	}
+
+	rel, err := filepath.Rel(n.root, evt.Name)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	if evt.Has(fsnotify.Create) {
+		if i, err := fs.Stat(n.w.fsys, rel); err == nil && i.IsDir() {
+			if !n.w.canDescend(rel) {
+				return false
+			}
+
+			if err := n.addDirs(rel); err != nil {
+				n.w.reportError(err)
+			}
+
+			return true
+		}
+	}
+
+	if _, ok := n.dirs[rel]; ok {
+		if evt.Has(fsnotify.Remove) || evt.Has(fsnotify.Rename) {
+			delete(n.dirs, rel)
+		}
+		return true
+	}
+
+	if n.w.currentPattern().Match(rel) {
+		return true
+	}
+
+	n.w.mu.RLock()
+	defer n.w.mu.RUnlock()
+
+	_, ok := n.w.watched[rel]
+	return ok
+}
+
+// drain handles all pending fsnotify events so that a burst of events
+// triggers a single change detection. It reports whether any of the events
+// (or relevant) requires a change detection.
+func (n *notifier) drain(relevant bool) bool {
+	for {
+		select {
+		case evt := <-n.nw.Events:
+			if n.handle(evt) {
+				relevant = true
+			}
+		default:
+			return relevant
+		}
+	}
+}
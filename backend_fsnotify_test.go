@@ -0,0 +1,77 @@
+//go:build with_fsnotify
+
+package globwatch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_fsnotify(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(dir, "cmd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Use a long interval so that changes can only be detected via fsnotify.
+	watcher, err := globwatch.New(os.DirFS(dir), "**/*_test.go", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	// Give the backend some time to add the directories.
+	time.Sleep(50 * time.Millisecond)
+
+	// Not matching the pattern
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "util.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "main_test.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new directory is watched, too.
+	if err := os.Mkdir(filepath.Join(dir, "internal"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(dir, "internal", "tool_test.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	evts := make([]globwatch.Event, 0, 2)
+	timeout := time.After(5 * time.Second)
+
+	for len(evts) < 2 {
+		select {
+		case evt := <-watcher.C():
+			evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
+		case <-timeout:
+			t.Fatalf("timeout waiting for events; got %v", evts)
+		}
+	}
+
+	ExpectThat(t, evts).Is(DeepEqual([]globwatch.Event{
+		{Type: globwatch.Created, Path: "cmd/main_test.go"},
+		{Type: globwatch.Created, Path: "internal/tool_test.go"},
+	}))
+}
@@ -0,0 +1,11 @@
+//go:build !with_fsnotify
+
+package globwatch
+
+import "context"
+
+// run detects changes until w is closed or ctx is done. The default backend
+// polls the filesystem every interval.
+func (w *Watcher) run(ctx context.Context) {
+	w.poll(ctx)
+}
@@ -0,0 +1,41 @@
+package globwatch
+
+import (
+	"time"
+)
+
+// nextInterval returns the interval to wait before the next poll. If an
+// error backoff has been configured with WithErrorBackoff and the previous
+// polls failed, the interval starts at the backoff's base and doubles with
+// each consecutive failure up to the backoff's max. Otherwise, the regular
+// interval is returned.
+func (w *Watcher) nextInterval() time.Duration {
+	if w.backoffBase <= 0 || w.failures == 0 {
+		w.backoffInterval.Store(0)
+		return w.interval
+	}
+
+	d := w.backoffBase
+	for i := 1; i < w.failures && d < w.backoffMax; i++ {
+		d *= 2
+	}
+
+	if d > w.backoffMax {
+		d = w.backoffMax
+	}
+
+	w.backoffInterval.Store(int64(d))
+
+	return d
+}
+
+// CurrentInterval returns the interval w currently waits between two polls.
+// This is the interval passed to New unless polling is backed off due to
+// errors (see WithErrorBackoff).
+func (w *Watcher) CurrentInterval() time.Duration {
+	if d := w.backoffInterval.Load(); d > 0 {
+		return time.Duration(d)
+	}
+
+	return w.interval
+}
@@ -0,0 +1,65 @@
+package globwatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_WithErrorBackoff(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := New(fsys, "**/*.go", time.Second, WithErrorBackoff(2*time.Second, 5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.ctx = context.Background()
+
+	ExpectThat(t, watcher.CurrentInterval()).Is(Equal(time.Second))
+
+	var intervals []time.Duration
+	for _, err := range []error{errIO, errIO, errIO, errIO, nil} {
+		watcher.failed(err)
+		intervals = append(intervals, watcher.nextInterval())
+		ExpectThat(t, watcher.CurrentInterval()).Is(Equal(intervals[len(intervals)-1]))
+	}
+
+	ExpectThat(t, intervals).Is(DeepEqual([]time.Duration{
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+		time.Second,
+	}))
+}
+
+func TestWatcher_WithErrorBackoff_invalid(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	for _, opt := range []Option{
+		WithErrorBackoff(-time.Second, 5*time.Second),
+		WithErrorBackoff(10*time.Millisecond, 0),
+		WithErrorBackoff(5*time.Second, 2*time.Second),
+	} {
+		_, err := New(fsys, "**/*.go", time.Second, opt)
+		ExpectThat(t, err).Is(Error(ErrInvalidOption))
+	}
+}
+
+func TestWatcher_nextInterval_noBackoff(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.ctx = context.Background()
+
+	watcher.failed(errIO)
+	ExpectThat(t, watcher.nextInterval()).Is(Equal(time.Second))
+	ExpectThat(t, watcher.CurrentInterval()).Is(Equal(time.Second))
+}
@@ -0,0 +1,189 @@
+package globwatch
+
+import (
+	"fmt"
+)
+
+// OnEvent registers fn to be invoked for every event emitted by w. Handlers
+// are invoked from dedicated worker goroutines (see WithCallbackWorkers) so
+// they never block change detection. A panic raised by fn is recovered and
+// reported as ErrHandlerPanic.
+func (w *Watcher) OnEvent(fn func(Event)) {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	w.eventHandlers = append(w.eventHandlers, fn)
+}
+
+// OnError registers fn to be invoked for every error reported by w. Error
+// handlers are invoked from the same worker goroutines as event handlers.
+func (w *Watcher) OnError(fn func(error)) {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	w.errorHandlers = append(w.errorHandlers, fn)
+}
+
+// OnCreate registers fn to be invoked for every Created event emitted by w.
+// Unlike handlers registered via OnEvent, fn is invoked in addition to
+// delivering the event to C even if WithCallbacksOnly is enabled. OnCreate
+// returns w to allow chaining.
+func (w *Watcher) OnCreate(fn func(Event)) *Watcher {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	w.createHandlers = append(w.createHandlers, fn)
+	return w
+}
+
+// OnModify registers fn to be invoked for every Modified and Truncated event
+// emitted by w. See OnCreate for details.
+func (w *Watcher) OnModify(fn func(Event)) *Watcher {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	w.modifyHandlers = append(w.modifyHandlers, fn)
+	return w
+}
+
+// OnDelete registers fn to be invoked for every Deleted event emitted by w.
+// See OnCreate for details.
+func (w *Watcher) OnDelete(fn func(Event)) *Watcher {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	w.deleteHandlers = append(w.deleteHandlers, fn)
+	return w
+}
+
+// handlers returns the currently registered handlers.
+func (w *Watcher) handlers() ([]func(Event), []func(error)) {
+	w.handlersMu.RLock()
+	defer w.handlersMu.RUnlock()
+
+	return w.eventHandlers, w.errorHandlers
+}
+
+// startCallbackWorkers starts the goroutines invoking the handlers. Each
+// start creates a new jobs channel as stopCallbackWorkers closes the
+// previous one.
+func (w *Watcher) startCallbackWorkers() {
+	callbacks := make(chan func(), 10)
+	w.callbacks = callbacks
+
+	for i := 0; i < w.callbackWorkers; i++ {
+		w.callbackWG.Add(1)
+		go func() {
+			defer w.callbackWG.Done()
+
+			for job := range callbacks {
+				job()
+			}
+		}()
+	}
+}
+
+// stopCallbackWorkers stops all workers after they have processed all
+// pending jobs.
+func (w *Watcher) stopCallbackWorkers() {
+	close(w.callbacks)
+	w.callbackWG.Wait()
+}
+
+// dispatchEvent queues evt for delivery to all event handlers. It returns
+// whether any handler has been registered.
+func (w *Watcher) dispatchEvent(evt Event) bool {
+	eventHandlers, _ := w.handlers()
+	if len(eventHandlers) == 0 {
+		return false
+	}
+
+	w.callbacks <- func() {
+		for _, fn := range eventHandlers {
+			w.callEventHandler(fn, evt)
+		}
+	}
+
+	return true
+}
+
+// typedHandlers returns the handlers registered for events of type t.
+func (w *Watcher) typedHandlers(t EventType) []func(Event) {
+	w.handlersMu.RLock()
+	defer w.handlersMu.RUnlock()
+
+	switch t {
+	case Created:
+		return w.createHandlers
+	case Modified, Truncated:
+		return w.modifyHandlers
+	case Deleted:
+		return w.deleteHandlers
+	default:
+		return nil
+	}
+}
+
+// dispatchTypedEvent queues evt for delivery to all handlers registered for
+// evt's type.
+func (w *Watcher) dispatchTypedEvent(evt Event) {
+	handlers := w.typedHandlers(evt.Type)
+	if len(handlers) == 0 {
+		return
+	}
+
+	w.callbacks <- func() {
+		for _, fn := range handlers {
+			w.callEventHandler(fn, evt)
+		}
+	}
+}
+
+// reportError reports err to all error handlers and to the errors channel.
+func (w *Watcher) reportError(err error) {
+	w.recordError()
+
+	_, errorHandlers := w.handlers()
+	if len(errorHandlers) > 0 {
+		w.callbacks <- func() {
+			w.callErrorHandlers(errorHandlers, err)
+		}
+
+		if w.callbacksOnly {
+			return
+		}
+	}
+
+	w.errors <- err
+}
+
+func (w *Watcher) callEventHandler(fn func(Event), evt Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+
+			// This runs on a worker goroutine so the error handlers are called
+			// directly rather than being queued.
+			_, errorHandlers := w.handlers()
+			if len(errorHandlers) > 0 {
+				w.callErrorHandlers(errorHandlers, err)
+				return
+			}
+
+			w.errors <- err
+		}
+	}()
+
+	fn(evt)
+}
+
+func (w *Watcher) callErrorHandlers(errorHandlers []func(error), err error) {
+	for _, fn := range errorHandlers {
+		func() {
+			// A panicking error handler cannot be reported to itself. Such panics
+			// are silently discarded.
+			defer func() { recover() }()
+			fn(err)
+		}()
+	}
+}
@@ -0,0 +1,176 @@
+package globwatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpointVersion is the version of the checkpoint file format.
+const checkpointVersion = 1
+
+// checkpoint defines the JSON representation of a checkpoint file.
+type checkpoint struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`
+}
+
+// SaveCheckpoint saves the modification times of all files currently
+// tracked by w to the file path. The file is written atomically by writing
+// to a temporary file first and renaming it afterwards. Use LoadCheckpoint
+// to restore the state after a restart. The file contains a JSON object
+// such as
+//
+//	{"version":1,"files":{"cmd/main.go":"2022-11-12T19:28:18.123456789Z"}}
+func (w *Watcher) SaveCheckpoint(path string) error {
+	w.mu.RLock()
+	cp := checkpoint{
+		Version: checkpointVersion,
+		Files:   make(map[string]string, len(w.modtimes)),
+	}
+	for name, modtime := range w.modtimes {
+		cp.Files[name] = modtime.Format(time.RFC3339Nano)
+	}
+	w.mu.RUnlock()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCheckpoint restores the modification times of tracked files from the
+// checkpoint file path written by SaveCheckpoint. It must be called before w
+// is started; it returns ErrAlreadyStarted otherwise. When started, w does
+// not report Created events for the restored files. Instead, the first
+// change detection reports all changes made since the checkpoint has been
+// saved. LoadCheckpoint returns an error if the file cannot be read or is
+// not a valid checkpoint.
+func (w *Watcher) LoadCheckpoint(path string) error {
+	if w.running.Load() {
+		return ErrAlreadyStarted
+	}
+
+	return w.restoreCheckpoint(path)
+}
+
+// restoreCheckpoint restores the modification times of tracked files from
+// the checkpoint file path.
+func (w *Watcher) restoreCheckpoint(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	if cp.Version != checkpointVersion {
+		return fmt.Errorf("invalid checkpoint %s: unsupported version %d", path, cp.Version)
+	}
+
+	modtimes := make(map[string]time.Time, len(cp.Files))
+	for name, s := range cp.Files {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("invalid checkpoint %s: %w", path, err)
+		}
+		modtimes[name] = t
+	}
+
+	w.mu.Lock()
+	w.modtimes = modtimes
+	w.mu.Unlock()
+
+	w.restored = true
+
+	return nil
+}
+
+// loadCheckpointFile loads the checkpoint file set with WithCheckpointFile
+// if it exists.
+func (w *Watcher) loadCheckpointFile() error {
+	if w.checkpointFile == "" || w.restored {
+		return nil
+	}
+
+	err := w.restoreCheckpoint(w.checkpointFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// startCheckpointing starts saving the checkpoint file set with
+// WithCheckpointFile every checkpoint interval.
+func (w *Watcher) startCheckpointing() {
+	if w.checkpointFile == "" {
+		return
+	}
+
+	w.checkpointDone = make(chan struct{})
+	w.checkpointWG.Add(1)
+
+	go func() {
+		defer w.checkpointWG.Done()
+
+		ticker := time.NewTicker(w.checkpointInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.SaveCheckpoint(w.checkpointFile); err != nil {
+					w.reportError(err)
+				}
+			case <-w.checkpointDone:
+				return
+			}
+		}
+	}()
+}
+
+// stopCheckpointing stops saving the checkpoint file periodically and saves
+// it a final time.
+func (w *Watcher) stopCheckpointing() {
+	if w.checkpointFile == "" {
+		return
+	}
+
+	close(w.checkpointDone)
+	w.checkpointWG.Wait()
+
+	if err := w.SaveCheckpoint(w.checkpointFile); err != nil {
+		w.reportError(err)
+	}
+}
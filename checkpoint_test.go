@@ -0,0 +1,125 @@
+package globwatch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_Checkpoint(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.EmptyFile("tool.go"),
+	))
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Close()
+
+	ExpectThat(t, watcher.SaveCheckpoint(path)).Is(NoError())
+
+	restored, err := globwatch.New(fsys, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, restored.LoadCheckpoint(path)).Is(NoError())
+
+	roundTrip := filepath.Join(t.TempDir(), "checkpoint.json")
+	ExpectThat(t, restored.SaveCheckpoint(roundTrip)).Is(NoError())
+
+	want, _ := os.ReadFile(path)
+	got, _ := os.ReadFile(roundTrip)
+	ExpectThat(t, string(got)).Is(Equal(string(want)))
+
+	time.Sleep(time.Millisecond)
+	fsys.Touch("tool.go")
+
+	if err := restored.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+
+	evt := <-restored.C()
+	ExpectThat(t, globwatch.Event{Type: evt.Type, Path: evt.Path}).Is(DeepEqual(globwatch.Event{
+		Type: globwatch.Modified,
+		Path: "tool.go",
+	}))
+}
+
+func TestWatcher_LoadCheckpoint_invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	watcher, err := globwatch.New(fsmock.New(fsmock.NewDir("")), "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted := filepath.Join(dir, "corrupted.json")
+	if err := os.WriteFile(corrupted, []byte(`{"version":1,"files":{"main.go":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ExpectThat(t, watcher.LoadCheckpoint(corrupted)).Is(NotNil())
+
+	unsupported := filepath.Join(dir, "unsupported.json")
+	if err := os.WriteFile(unsupported, []byte(`{"version":2,"files":{}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ExpectThat(t, watcher.LoadCheckpoint(unsupported)).Is(NotNil())
+}
+
+func TestWatcher_WithCheckpointFile(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond, globwatch.WithCheckpointFile(path, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Close()
+
+	restored, err := globwatch.New(fsys, "*.go", time.Millisecond,
+		globwatch.WithCheckpointFile(path, time.Hour),
+		globwatch.WithInitialEvents(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := restored.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	restored.Close()
+
+	var events []globwatch.Event
+	for evt := range restored.C() {
+		events = append(events, evt)
+	}
+	ExpectThat(t, len(events)).Is(Equal(0))
+
+	_, err = globwatch.New(fsys, "*.go", time.Millisecond, globwatch.WithCheckpointFile(path, 0))
+	ExpectThat(t, err).Is(Error(globwatch.ErrInvalidOption))
+}
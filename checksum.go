@@ -0,0 +1,32 @@
+package globwatch
+
+import (
+	"bytes"
+	"io"
+)
+
+// updateChecksum computes the checksum of the file name using the hash
+// configured with WithChecksumDetection and stores it if commit is set. It
+// reports whether the checksum differs from the one previously stored. A
+// file without a previously stored checksum is reported as changed. It must
+// be called with mu being held.
+func (w *Watcher) updateChecksum(name string, commit bool) (bool, error) {
+	f, err := w.fsys.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	h := w.newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return false, err
+	}
+
+	sum := h.Sum(nil)
+	old, ok := w.checksums[name]
+	if commit {
+		w.checksums[name] = sum
+	}
+
+	return !ok || !bytes.Equal(old, sum), nil
+}
@@ -0,0 +1,67 @@
+// Package cli provides helpers for command line tools creating a
+// globwatch.Watcher from command line flags. RegisterFlags registers the
+// flags with a flag.FlagSet or any other flag set implementing Flags:
+//
+//	flags := cli.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//
+//	watcher, err := flags.Watcher(os.DirFS(flag.Arg(0)))
+//
+// Commands built with github.com/spf13/cobra pass cmd.Flags() to
+// RegisterFlags or use the github.com/halimath/globwatch/cli/cobracli module.
+package cli
+
+import (
+	"io/fs"
+	"time"
+
+	"github.com/halimath/globwatch"
+)
+
+const (
+	// DefaultPattern is the default value of the pattern flag.
+	DefaultPattern = "**/*"
+	// DefaultInterval is the default value of the interval flag.
+	DefaultInterval = time.Second
+)
+
+// Flags defines the methods used to register flags. It is implemented by
+// *flag.FlagSet as well as *pflag.FlagSet as used by cobra.
+type Flags interface {
+	StringVar(p *string, name string, value string, usage string)
+	DurationVar(p *time.Duration, name string, value time.Duration, usage string)
+}
+
+// FlagSet holds the values of the flags registered with RegisterFlags.
+type FlagSet struct {
+	pattern  string
+	interval time.Duration
+}
+
+// RegisterFlags registers the flags --pattern (defaults to DefaultPattern)
+// and --interval (defaults to DefaultInterval) with flags. Once flags has
+// been parsed, use the returned FlagSet to create a Watcher.
+func RegisterFlags(flags Flags) *FlagSet {
+	f := &FlagSet{}
+
+	flags.StringVar(&f.pattern, "pattern", DefaultPattern, "Pattern of files to watch")
+	flags.DurationVar(&f.interval, "interval", DefaultInterval, "Interval to check for changes")
+
+	return f
+}
+
+// Pattern returns the value of the pattern flag.
+func (f *FlagSet) Pattern() string {
+	return f.pattern
+}
+
+// Interval returns the value of the interval flag.
+func (f *FlagSet) Interval() time.Duration {
+	return f.interval
+}
+
+// Watcher creates a new Watcher for fsys using the pattern and interval
+// given as flags. opts are passed to globwatch.New.
+func (f *FlagSet) Watcher(fsys fs.FS, opts ...globwatch.Option) (*globwatch.Watcher, error) {
+	return globwatch.New(fsys, f.pattern, f.interval, opts...)
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := RegisterFlags(flags)
+
+	ExpectThat(t, flags.Parse(nil)).Is(NoError())
+	ExpectThat(t, f.Pattern()).Is(Equal("**/*"))
+	ExpectThat(t, f.Interval()).Is(Equal(time.Second))
+
+	flags = flag.NewFlagSet("test", flag.ContinueOnError)
+	f = RegisterFlags(flags)
+
+	ExpectThat(t, flags.Parse([]string{"--pattern", "*.go", "--interval", "5ms"})).Is(NoError())
+	ExpectThat(t, f.Pattern()).Is(Equal("*.go"))
+	ExpectThat(t, f.Interval()).Is(Equal(5 * time.Millisecond))
+
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.EmptyFile("README.md"),
+	))
+
+	watcher, err := f.Watcher(fsys, globwatch.WithInitialEvents(true))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, watcher.CurrentInterval()).Is(Equal(5 * time.Millisecond))
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	evt := <-watcher.C()
+	ExpectThat(t, evt.Path).Is(Equal("main.go"))
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"main.go"}))
+}
+
+func TestRegisterFlags_invalidPattern(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := RegisterFlags(flags)
+
+	ExpectThat(t, flags.Parse([]string{"--pattern", "[a-"})).Is(NoError())
+
+	_, err := f.Watcher(fsmock.New(fsmock.NewDir("")))
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,25 @@
+// Package cobracli registers the flags defined by package
+// github.com/halimath/globwatch/cli with a cobra.Command. It is provided as a
+// separate module so that users of globwatch do not depend on cobra:
+//
+//	cmd := &cobra.Command{
+//		Use: "watch <dir>",
+//		RunE: func(cmd *cobra.Command, args []string) error {
+//			watcher, err := flags.Watcher(os.DirFS(args[0]))
+//			// ...
+//		},
+//	}
+//	flags = cobracli.RegisterFlags(cmd)
+package cobracli
+
+import (
+	"github.com/halimath/globwatch/cli"
+	"github.com/spf13/cobra"
+)
+
+// RegisterFlags registers the same flags as cli.RegisterFlags with cmd's
+// flag set. Once cmd's flags have been parsed, i.e. inside cmd's Run
+// function, use the returned FlagSet to create a Watcher.
+func RegisterFlags(cmd *cobra.Command) *cli.FlagSet {
+	return cli.RegisterFlags(cmd.Flags())
+}
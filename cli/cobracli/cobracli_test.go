@@ -0,0 +1,36 @@
+package cobracli
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+		Run: func(*cobra.Command, []string) {},
+	}
+	got := RegisterFlags(cmd)
+
+	cmd.SetArgs(nil)
+	cmd.SetOut(io.Discard)
+	ExpectThat(t, cmd.Execute()).Is(NoError())
+	ExpectThat(t, got.Pattern()).Is(Equal("**/*"))
+	ExpectThat(t, got.Interval()).Is(Equal(time.Second))
+
+	cmd = &cobra.Command{
+		Use: "test",
+		Run: func(*cobra.Command, []string) {},
+	}
+	got = RegisterFlags(cmd)
+
+	cmd.SetArgs([]string{"--pattern", "**/*.go", "--interval", "2s"})
+	ExpectThat(t, cmd.Execute()).Is(NoError())
+	ExpectThat(t, got.Pattern()).Is(Equal("**/*.go"))
+	ExpectThat(t, got.Interval()).Is(Equal(2 * time.Second))
+}
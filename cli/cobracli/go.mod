@@ -0,0 +1,19 @@
+module github.com/halimath/globwatch/cli/cobracli
+
+go 1.23
+
+require (
+	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
+	github.com/halimath/globwatch v0.0.0-20261015125312-e713d88eae2c
+	github.com/spf13/cobra v1.8.1
+)
+
+require (
+	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
+
+replace github.com/halimath/globwatch => ../..
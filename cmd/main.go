@@ -7,26 +7,48 @@
 //
 // This starts the detection which runs until SIGINT is received which causes
 // the app to do a graceful shutdown.
+//
+// The validate subcommand checks a pattern's syntax without watching any
+// directory:
+//
+//	globwatch validate [--json] <pattern>
+//
+// It prints OK along with the pattern's number of tokens and complexity or
+// the error message and exits with status 1 if the pattern is invalid. With
+// --json the result is printed as a JSON object.
+//
+// The lint subcommand checks patterns for common mistakes:
+//
+//	globwatch lint <pattern>...
+//
+// It prints a warning along with a suggested correction for each mistake
+// found and exits with status 1 if any warning has been printed.
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
-	"time"
 
-	"github.com/halimath/globwatch"
+	"github.com/halimath/globwatch/cli"
+	"github.com/halimath/globwatch/pattern"
 )
 
-var (
-	pattern  = flag.String("pattern", "**/*", "Pattern of files to watch")
-	interval = flag.Duration("interval", time.Second, "Interval to check for changes")
-)
+var flags = cli.RegisterFlags(flag.CommandLine)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(lint(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	if flag.NArg() != 1 {
@@ -41,7 +63,7 @@ func main() {
 		os.Exit(2)
 	}
 
-	watcher, err := globwatch.New(os.DirFS(dir), *pattern, *interval)
+	watcher, err := flags.Watcher(os.DirFS(dir))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s: unable to create watcher: %s\n", os.Args[0], err)
 		os.Exit(2)
@@ -71,3 +93,70 @@ func main() {
 
 	watcher.Close()
 }
+
+// validateResult defines the JSON output of the validate subcommand.
+type validateResult struct {
+	Valid      bool   `json:"valid"`
+	Tokens     *int   `json:"tokens,omitempty"`
+	Complexity *int   `json:"complexity,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// validate implements the validate subcommand and returns the exit status.
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "%s: missing pattern\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s validate [--json] <PATTERN>\n", os.Args[0])
+		return 2
+	}
+
+	var result validateResult
+	pat, err := pattern.New(flags.Arg(0))
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Valid = true
+		tokens, complexity := pat.NumTokens(), pat.Complexity()
+		result.Tokens = &tokens
+		result.Complexity = &complexity
+	}
+
+	if *asJSON {
+		json.NewEncoder(os.Stdout).Encode(result)
+	} else if result.Valid {
+		fmt.Printf("OK (tokens: %d, complexity: %d)\n", *result.Tokens, *result.Complexity)
+	} else {
+		fmt.Println(result.Error)
+	}
+
+	if !result.Valid {
+		return 1
+	}
+
+	return 0
+}
+
+// lint implements the lint subcommand and returns the exit status.
+func lint(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "%s: missing pattern\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s lint <PATTERN>...\n", os.Args[0])
+		return 2
+	}
+
+	status := 0
+	for _, pat := range args {
+		for _, w := range pattern.Lint(pat) {
+			fmt.Printf("%s: %s\n", pat, w)
+			status = 1
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestMain(m *testing.M) {
+	// Run main instead of the tests when the test binary is executed by
+	// runCommand.
+	if os.Getenv("GLOBWATCH_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runCommand executes the test binary as the globwatch command with args and
+// returns its stdout and exit status.
+func runCommand(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "GLOBWATCH_TEST_MAIN=1")
+
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(out), 0
+}
+
+func TestValidate(t *testing.T) {
+	out, status := runCommand(t, "validate", "**/*.go")
+	ExpectThat(t, status).Is(Equal(0))
+	ExpectThat(t, out).Is(Equal("OK (tokens: 6, complexity: 5)\n"))
+
+	out, status = runCommand(t, "validate", "**/[a-")
+	ExpectThat(t, status).Is(Equal(1))
+	ExpectThat(t, out).Is(StringContaining("bad pattern"))
+
+	_, status = runCommand(t, "validate")
+	ExpectThat(t, status).Is(Equal(2))
+}
+
+func TestValidate_json(t *testing.T) {
+	out, status := runCommand(t, "validate", "--json", "**/*.go")
+	ExpectThat(t, status).Is(Equal(0))
+	ExpectThat(t, out).Is(Equal(`{"valid":true,"tokens":6,"complexity":5}` + "\n"))
+
+	out, status = runCommand(t, "validate", "--json", "main.go")
+	ExpectThat(t, status).Is(Equal(0))
+	ExpectThat(t, out).Is(Equal(`{"valid":true,"tokens":7,"complexity":0}` + "\n"))
+
+	out, status = runCommand(t, "validate", "--json", "**/[a-")
+	ExpectThat(t, status).Is(Equal(1))
+	ExpectThat(t, out).Is(StringContaining(`{"valid":false,"error":"bad pattern`))
+}
+
+func TestLint(t *testing.T) {
+	out, status := runCommand(t, "lint", "**/*.go", "src/**/*_test.go")
+	ExpectThat(t, status).Is(Equal(0))
+	ExpectThat(t, out).Is(Equal(""))
+
+	out, status = runCommand(t, "lint", "**/*.go", "**.go")
+	ExpectThat(t, status).Is(Equal(1))
+	ExpectThat(t, out).Is(Equal(`**.go: recursive-wildcard-segment: ** in "**.go" does not form a complete path segment (use "**/*.go")` + "\n"))
+
+	_, status = runCommand(t, "lint")
+	ExpectThat(t, status).Is(Equal(2))
+}
@@ -0,0 +1,114 @@
+package globwatch
+
+import (
+	"slices"
+	"time"
+)
+
+// publish emits events. If a coalesce window has been configured, events are
+// collected and emitted once the window has elapsed.
+func (w *Watcher) publish(events []Event) {
+	if w.coalesceWindow <= 0 {
+		for _, evt := range events {
+			w.emit(evt)
+		}
+		return
+	}
+
+	w.coalesceMu.Lock()
+	defer w.coalesceMu.Unlock()
+
+	for _, evt := range events {
+		i, ok := w.pendingIndex[evt.Path]
+		if !ok {
+			w.pendingIndex[evt.Path] = len(w.pending)
+			w.pending = append(w.pending, evt)
+			continue
+		}
+
+		merged, ok := coalesce(w.pending[i], evt)
+		if ok {
+			w.pending[i] = merged
+			continue
+		}
+
+		// The path's state is unchanged; drop the pending event.
+		w.pending = slices.Delete(w.pending, i, i+1)
+		delete(w.pendingIndex, evt.Path)
+		for p, j := range w.pendingIndex {
+			if j > i {
+				w.pendingIndex[p] = j - 1
+			}
+		}
+	}
+
+	if len(w.pending) > 0 && w.coalesceTimer == nil {
+		w.coalesceTimer = time.AfterFunc(w.coalesceWindow, w.flushCoalesced)
+	}
+}
+
+// coalesce merges the events old and new reported for the same path into a
+// single event describing the change from the state before old to the state
+// after new. It returns false if the state is unchanged, i.e. if a file has
+// been created and deleted again. Unless the resulting event is a Deleted
+// event, it carries new's file info.
+func coalesce(old, new Event) (Event, bool) {
+	switch {
+	case old.Type == Created && new.Type == Deleted:
+		return Event{}, false
+
+	case old.Type == Deleted && new.Type == Created:
+		// The file existed before and exists afterwards.
+		old.Type = Modified
+
+	case old.Type == Created:
+		// The file did not exist before.
+
+	case old.Type == Truncated && new.Type == Modified:
+		// Keep reporting the truncation.
+
+	default:
+		old.Type = new.Type
+	}
+
+	if old.Type == Deleted {
+		old.ModTime = time.Time{}
+		old.Size = 0
+	} else {
+		old.ModTime = new.ModTime
+		old.Size = new.Size
+	}
+
+	return old, true
+}
+
+// flushCoalesced emits all pending events once the coalesce window elapsed.
+// It is serialized with change detection. Once w is closing, pending events
+// are emitted by awaitScans.
+func (w *Watcher) flushCoalesced() {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	if w.closing.Load() {
+		return
+	}
+
+	w.flushPending()
+}
+
+// flushPending emits all pending events immediately.
+func (w *Watcher) flushPending() {
+	w.coalesceMu.Lock()
+	events := w.pending
+	w.pending = nil
+	clear(w.pendingIndex)
+	if w.coalesceTimer != nil {
+		w.coalesceTimer.Stop()
+		w.coalesceTimer = nil
+	}
+	w.coalesceMu.Unlock()
+
+	for _, evt := range events {
+		w.emit(evt)
+	}
+}
@@ -0,0 +1,224 @@
+package globwatch
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestCoalesce(t *testing.T) {
+	t0 := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+
+	tests := []struct {
+		old, new, want Event
+		ok             bool
+	}{
+		{
+			Event{Type: Modified, Path: "a.go", ModTime: t0, Size: 1},
+			Event{Type: Modified, Path: "a.go", ModTime: t1, Size: 2},
+			Event{Type: Modified, Path: "a.go", ModTime: t1, Size: 2},
+			true,
+		},
+		{
+			Event{Type: Created, Path: "a.go", ModTime: t0, Size: 1},
+			Event{Type: Modified, Path: "a.go", ModTime: t1, Size: 2},
+			Event{Type: Created, Path: "a.go", ModTime: t1, Size: 2},
+			true,
+		},
+		{
+			Event{Type: Modified, Path: "a.go", ModTime: t0, Size: 2},
+			Event{Type: Truncated, Path: "a.go", ModTime: t1, Size: 1},
+			Event{Type: Truncated, Path: "a.go", ModTime: t1, Size: 1},
+			true,
+		},
+		{
+			Event{Type: Truncated, Path: "a.go", ModTime: t0, Size: 1},
+			Event{Type: Modified, Path: "a.go", ModTime: t1, Size: 2},
+			Event{Type: Truncated, Path: "a.go", ModTime: t1, Size: 2},
+			true,
+		},
+		{
+			Event{Type: Modified, Path: "a.go", ModTime: t0, Size: 1},
+			Event{Type: Deleted, Path: "a.go"},
+			Event{Type: Deleted, Path: "a.go"},
+			true,
+		},
+		{
+			Event{Type: Created, Path: "a.go", ModTime: t0, Size: 1},
+			Event{Type: Deleted, Path: "a.go"},
+			Event{},
+			false,
+		},
+		{
+			Event{Type: Deleted, Path: "a.go"},
+			Event{Type: Created, Path: "a.go", ModTime: t1, Size: 2},
+			Event{Type: Modified, Path: "a.go", ModTime: t1, Size: 2},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		got, ok := coalesce(test.old, test.new)
+		ExpectThat(t, ok).Is(Equal(test.ok))
+		ExpectThat(t, got).Is(Equal(test.want))
+	}
+}
+
+// dupFS wraps a fsmock.FS and reports every file twice when reading a
+// directory. The second entry reports a modification time one second later
+// than the first one.
+type dupFS struct {
+	*fsmock.FS
+}
+
+func (d dupFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := d.FS.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dup := make([]fs.DirEntry, 0, 2*len(entries))
+	for _, e := range entries {
+		dup = append(dup, e)
+		if !e.IsDir() {
+			dup = append(dup, laterEntry{e})
+		}
+	}
+
+	return dup, nil
+}
+
+type laterEntry struct {
+	fs.DirEntry
+}
+
+func (e laterEntry) Info() (fs.FileInfo, error) {
+	i, err := e.DirEntry.Info()
+	if err != nil {
+		return nil, err
+	}
+	return laterInfo{i}, nil
+}
+
+type laterInfo struct {
+	fs.FileInfo
+}
+
+func (i laterInfo) ModTime() time.Time {
+	return i.FileInfo.ModTime().Add(time.Second)
+}
+
+func TestWatcher_WithCoalesceWindow(t *testing.T) {
+	detect := func(opts ...Option) []Event {
+		fsys := dupFS{fsmock.New(fsmock.NewDir("",
+			fsmock.EmptyFile("go.mod"),
+		))}
+
+		watcher, err := New(fsys, "*.go", time.Hour, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := watcher.determineInitialState(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		fsys.Touch("main.go")
+		watcher.detectChanges(context.Background())
+
+		// Wait for the coalesce window to elapse.
+		time.Sleep(20 * time.Millisecond)
+
+		evts := make([]Event, 0, len(watcher.c))
+		for len(watcher.c) > 0 {
+			evts = append(evts, <-watcher.c)
+		}
+
+		return withoutInfo(evts)
+	}
+
+	ExpectThat(t, detect()).Is(DeepEqual([]Event{
+		{Type: Created, Path: "main.go"},
+		{Type: Modified, Path: "main.go"},
+	}))
+
+	ExpectThat(t, detect(WithCoalesceWindow(time.Millisecond))).Is(DeepEqual([]Event{
+		{Type: Created, Path: "main.go"},
+	}))
+}
+
+func TestWatcher_WithCoalesceWindow_multipleScans(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.EmptyFile("main.go"),
+	))
+
+	watcher, err := New(fsys, "*.go", time.Hour, WithCoalesceWindow(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("main_test.go")
+	watcher.scan(watcher.ctx)
+
+	fsys.Touch("main.go")
+	fsys.Rm("main_test.go")
+	watcher.scan(watcher.ctx)
+
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+
+	// Pending events are emitted when closing.
+	watcher.Close()
+
+	evts, err := watcher.Drain()
+	ExpectThat(t, err).Is(NoError())
+	// main_test.go has been created and deleted again within the window.
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "main.go"},
+	}))
+}
+
+func TestWatcher_WithCoalesceWindow_recreate(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.EmptyFile("main.go"),
+		fsmock.EmptyFile("tool.go"),
+	))
+
+	watcher, err := New(fsys, "*.go", time.Hour, WithCoalesceWindow(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Rm("main.go")
+	fsys.Touch("tool_test.go")
+	watcher.scan(watcher.ctx)
+
+	fsys.Touch("main.go")
+	fsys.Rm("tool_test.go")
+	watcher.scan(watcher.ctx)
+
+	watcher.Close()
+
+	// The recreated file still exists and is still tracked.
+	evts, err := watcher.Drain()
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "main.go"},
+	}))
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"main.go", "tool.go"}))
+}
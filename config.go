@@ -0,0 +1,150 @@
+package globwatch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/halimath/globwatch/pattern"
+)
+
+// ConfigFile is the name of the file NewFromConfig reads patterns from.
+const ConfigFile = ".globwatchrc"
+
+// NewFromConfig creates a new watcher watching the operating system's
+// directory dir using the patterns read from the file ConfigFile located in
+// dir. The config file contains one pattern per line. Lines starting with #
+// are comments; blank lines are ignored. A pattern prefixed with ! excludes
+// all files matching it even if they match another pattern. A file is
+// watched if it matches any of the patterns and none of the exclusions:
+//
+//	# Go sources
+//	**/*.go
+//	!**/*_test.go
+//
+// The config file itself is watched, too. Once it changes, the patterns are
+// reloaded and used starting with the next change detection. Errors
+// encountered while reloading are reported via the errors channel and the
+// previous patterns stay in effect.
+func NewFromConfig(dir string, interval time.Duration, opts ...Option) (*Watcher, error) {
+	p, err := readConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := os.DirFS(dir)
+
+	w, err := New(fsys, "", interval, opts...)
+	if err != nil {
+		return nil, err
+	}
+	w.pat.Store(p)
+
+	configInterval := interval / 2
+	if configInterval <= 0 {
+		configInterval = interval
+	}
+
+	w.config, err = New(fsys, ConfigFile, configInterval, WithCallbacksOnly(true))
+	if err != nil {
+		return nil, err
+	}
+
+	w.config.OnEvent(func(evt Event) {
+		if evt.Type != Deleted {
+			w.reloadConfig(dir)
+		}
+	})
+	w.config.OnError(w.reportError)
+
+	return w, nil
+}
+
+// reloadConfig reads the config file from dir and replaces w's pattern. The
+// directory state recorded for incremental scans is discarded as it has been
+// filtered using the previous pattern.
+func (w *Watcher) reloadConfig(dir string) {
+	p, err := readConfig(dir)
+	if err != nil {
+		w.reportError(fmt.Errorf("failed to reload config: %w", err))
+		return
+	}
+
+	w.scanMu.Lock()
+	w.pat.Store(p)
+	w.mu.Lock()
+	w.shadow = nil
+	w.mu.Unlock()
+	w.scanMu.Unlock()
+
+	w.log(slog.LevelInfo, "config reloaded", slog.String("path", filepath.Join(dir, ConfigFile)))
+}
+
+// startConfigWatcher starts watching the config file if w has been created
+// using NewFromConfig.
+func (w *Watcher) startConfigWatcher(ctx context.Context) error {
+	if w.config == nil {
+		return nil
+	}
+
+	return w.config.StartContext(ctx)
+}
+
+// stopConfigWatcher stops watching the config file. It is a no-op if w has
+// not been created using NewFromConfig.
+func (w *Watcher) stopConfigWatcher() {
+	if w.config != nil {
+		w.config.Close()
+	}
+}
+
+// readConfig reads the config file from dir and returns the pattern it
+// defines.
+func readConfig(dir string) (*pattern.Pattern, error) {
+	name := filepath.Join(dir, ConfigFile)
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var includes, excludes []*pattern.Pattern
+
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		exclude := strings.HasPrefix(line, "!")
+		p, err := pattern.New(strings.TrimPrefix(line, "!"))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+
+		if exclude {
+			excludes = append(excludes, p)
+		} else {
+			includes = append(includes, p)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	// Any with no patterns never matches; no patterns means no files.
+	p := pattern.Any(includes...)
+	if len(excludes) > 0 {
+		p = pattern.All(p, pattern.Not(pattern.Any(excludes...)))
+	}
+
+	return p, nil
+}
@@ -0,0 +1,70 @@
+package globwatch
+
+import (
+	"sort"
+	"time"
+)
+
+// Snapshot returns a copy of the modification times of all files currently
+// tracked by w keyed by path. Use Diff to compute the changes between two
+// snapshots.
+func (w *Watcher) Snapshot() map[string]time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	snapshot := make(map[string]time.Time, len(w.modtimes))
+	for name, modtime := range w.modtimes {
+		snapshot[w.externalPath(name)] = modtime
+	}
+
+	return snapshot
+}
+
+// Diff computes the events that describe the transition from state before
+// to state after. Both maps contain file modification times keyed by path as
+// returned from Snapshot. Created and Modified events carry the modification
+// time from after but no size. Created and Modified events are returned first
+// followed by Deleted events; each group is sorted by path.
+func Diff(before, after map[string]time.Time) []Event {
+	events := make([]Event, 0)
+
+	for _, name := range sortedKeys(after) {
+		got, ok := before[name]
+		if !ok {
+			events = append(events, Event{
+				Type:    Created,
+				Path:    name,
+				ModTime: after[name],
+			})
+			continue
+		}
+
+		if after[name].After(got) {
+			events = append(events, Event{
+				Type:    Modified,
+				Path:    name,
+				ModTime: after[name],
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(before) {
+		if _, ok := after[name]; !ok {
+			events = append(events, Event{
+				Type: Deleted,
+				Path: name,
+			})
+		}
+	}
+
+	return events
+}
+
+func sortedKeys(m map[string]time.Time) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,42 @@
+package globwatch
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestDiff(t *testing.T) {
+	t0 := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+
+	before := map[string]time.Time{
+		"a.go": t0,
+		"b.go": t0,
+		"c.go": t0,
+	}
+
+	after := map[string]time.Time{
+		"a.go": t0,
+		"c.go": t1,
+		"d.go": t1,
+	}
+
+	ExpectThat(t, Diff(before, after)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "c.go", ModTime: t1},
+		{Type: Created, Path: "d.go", ModTime: t1},
+		{Type: Deleted, Path: "b.go"},
+	}))
+}
+
+func TestDiff_empty(t *testing.T) {
+	t0 := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	state := map[string]time.Time{
+		"a.go": t0,
+	}
+
+	ExpectThat(t, Diff(state, state)).Is(DeepEqual([]Event{}))
+	ExpectThat(t, Diff(nil, nil)).Is(DeepEqual([]Event{}))
+}
@@ -0,0 +1,109 @@
+package globwatch
+
+import (
+	"strings"
+	"sync"
+)
+
+// dirDiscovery keeps track of the directories beyond the walk depth in order
+// to detect newly created ones. See WithAutoDiscoverDepth.
+type dirDiscovery struct {
+	mu sync.Mutex
+	// whether a walk is in progress
+	active bool
+	// whether the walk in progress is a dry run which does not record the
+	// directories seen
+	dryRun bool
+	// whether a walk has completed so that known is populated
+	initialized bool
+	// directories beyond the walk depth seen during the last completed walk
+	known map[string]struct{}
+	// directories beyond the walk depth seen during the current walk
+	seen map[string]struct{}
+	// directories beyond the walk depth that have been discovered and are
+	// walked
+	discovered map[string]struct{}
+}
+
+// beginDiscovery starts tracking the directories seen during a walk. If
+// dryRun is set, new directories are walked but not recorded.
+func (w *Watcher) beginDiscovery(dryRun bool) {
+	if w.autoDiscoverDepth <= 0 {
+		return
+	}
+
+	w.discovery.mu.Lock()
+	defer w.discovery.mu.Unlock()
+
+	w.discovery.active = true
+	w.discovery.dryRun = dryRun
+	w.discovery.seen = make(map[string]struct{})
+}
+
+// endDiscovery ends tracking the directories seen during a walk. If the walk
+// completed, the directories seen become the known directories for the next
+// walk and discovered directories that no longer exist are forgotten.
+func (w *Watcher) endDiscovery(completed bool) {
+	if w.autoDiscoverDepth <= 0 {
+		return
+	}
+
+	w.discovery.mu.Lock()
+	defer w.discovery.mu.Unlock()
+
+	if completed && !w.discovery.dryRun {
+		w.discovery.known = w.discovery.seen
+		w.discovery.initialized = true
+
+		for dir := range w.discovery.discovered {
+			if _, ok := w.discovery.known[dir]; !ok {
+				delete(w.discovery.discovered, dir)
+			}
+		}
+	}
+
+	w.discovery.active = false
+	w.discovery.dryRun = false
+	w.discovery.seen = nil
+}
+
+// discoverDir reports whether the directory dir which lies beyond the walk
+// depth is walked nevertheless because it has been discovered. A directory
+// is discovered if it has not been seen during the previous walk and it does
+// not exceed the walk depth by more than the auto discover depth.
+func (w *Watcher) discoverDir(dir string) bool {
+	if w.autoDiscoverDepth <= 0 || strings.Count(dir, "/")+1 > w.walkDepth+w.autoDiscoverDepth {
+		return false
+	}
+
+	w.discovery.mu.Lock()
+	defer w.discovery.mu.Unlock()
+
+	_, discovered := w.discovery.discovered[dir]
+	if !w.discovery.active {
+		return discovered
+	}
+
+	_, known := w.discovery.known[dir]
+	isNew := w.discovery.initialized && !known
+
+	if w.discovery.dryRun {
+		return discovered || isNew
+	}
+
+	w.discovery.seen[dir] = struct{}{}
+
+	if discovered {
+		return true
+	}
+
+	if isNew {
+		if w.discovery.discovered == nil {
+			w.discovery.discovered = make(map[string]struct{})
+		}
+		w.discovery.discovered[dir] = struct{}{}
+		return true
+	}
+
+	return false
+}
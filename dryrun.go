@@ -0,0 +1,33 @@
+package globwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DryRun performs a single change detection and returns the events that
+// would be emitted without emitting them. The events are neither sent to C
+// nor to subscribers or event handlers and the state tracked by w is left
+// unchanged, so the next change detection reports the same changes.
+//
+// DryRun may be called before w has been started, in which case all matching
+// files are reported as Created. It returns the events found along with all
+// non-fatal errors joined. If the filesystem cannot be walked, an error is
+// returned and no events are reported.
+func (w *Watcher) DryRun() ([]Event, error) {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	events := make([]Event, 0)
+
+	_, errs, err := w.detectChangesInto(context.Background(), true, func(evt Event) {
+		evt.Path = w.externalPath(evt.Path)
+		events = append(events, evt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect changes: %w", err)
+	}
+
+	return events, errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package globwatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_DryRun(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("a.go"),
+		fsmock.EmptyFile("b.go"),
+		fsmock.EmptyFile("c.go"),
+	))
+
+	watcher, err := New(fsys, "*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evts, err := watcher.DryRun()
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "a.go"},
+		{Type: Created, Path: "b.go"},
+		{Type: Created, Path: "c.go"},
+	}))
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("a.go")
+	fsys.Rm("b.go")
+	fsys.Touch("d.go")
+
+	evts, err = watcher.DryRun()
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "d.go"},
+		{Type: Modified, Path: "a.go"},
+		{Type: Deleted, Path: "b.go"},
+	}))
+
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"a.go", "b.go", "c.go"}))
+
+	if err := watcher.detectChanges(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	close(watcher.c)
+
+	var emitted []Event
+	for evt := range watcher.c {
+		emitted = append(emitted, evt)
+	}
+
+	ExpectThat(t, withoutInfo(emitted)).Is(DeepEqual(withoutInfo(evts)))
+}
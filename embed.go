@@ -0,0 +1,27 @@
+package globwatch
+
+import (
+	"crypto/sha256"
+	"embed"
+	"io/fs"
+	"time"
+)
+
+// NewEmbed creates a new watcher watching the embedded filesystem fsys. As
+// files contained in an embed.FS report a zero modification time, the
+// watcher detects changes by comparing checksums of the files' contents (see
+// WithChecksumDetection) using SHA-256. Pass WithChecksumDetection in opts to
+// use a different hash.
+//
+// Note that an embed.FS never changes at runtime, so the watcher only ever
+// reports the initial state. NewEmbed is useful for tests and demos that
+// exercise code consuming a Watcher with files compiled into the binary.
+func NewEmbed(fsys embed.FS, pat string, interval time.Duration, opts ...Option) (*Watcher, error) {
+	return newEmbed(fsys, pat, interval, opts...)
+}
+
+// newEmbed implements NewEmbed for any fs.FS reporting zero modification
+// times.
+func newEmbed(fsys fs.FS, pat string, interval time.Duration, opts ...Option) (*Watcher, error) {
+	return New(fsys, pat, interval, append([]Option{WithChecksumDetection(sha256.New)}, opts...)...)
+}
@@ -0,0 +1,57 @@
+package globwatch
+
+import (
+	"context"
+	"embed"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "github.com/halimath/expect-go"
+)
+
+//go:embed go.mod
+var embedded embed.FS
+
+func TestNewEmbed(t *testing.T) {
+	watcher, err := NewEmbed(embedded, "*.mod", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"go.mod"}))
+}
+
+func TestNewEmbed_modified(t *testing.T) {
+	// Like an embed.FS, the files report a zero modification time.
+	fsys := fstest.MapFS{
+		"main.go": &fstest.MapFile{Data: []byte("package main")},
+		"tool.go": &fstest.MapFile{Data: []byte("package main")},
+	}
+
+	watcher, err := newEmbed(fsys, "*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys["main.go"] = &fstest.MapFile{Data: []byte("package main\n\nfunc main() {}")}
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, len(watcher.c)).Is(Equal(1))
+
+	evt := <-watcher.c
+	ExpectThat(t, evt.Type).Is(Equal(Modified))
+	ExpectThat(t, evt.Path).Is(Equal("main.go"))
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+}
@@ -0,0 +1,203 @@
+package globwatch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// eventLog writes events as newline delimited JSON.
+type eventLog struct {
+	mu  sync.Mutex
+	out io.Writer
+	enc *json.Encoder
+	now func() time.Time
+
+	// Buffer and file of the log file opened for WithEventLog
+	buf  *bufio.Writer
+	file *os.File
+}
+
+// eventLogEntry defines the JSON representation of a logged event.
+type eventLogEntry struct {
+	Type    EventType  `json:"type"`
+	Path    string     `json:"path"`
+	ModTime *time.Time `json:"modTime,omitempty"`
+	Size    int64      `json:"size,omitempty"`
+	Time    time.Time  `json:"time"`
+}
+
+func (l *eventLog) write(evt Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := eventLogEntry{
+		Type: evt.Type,
+		Path: evt.Path,
+		Size: evt.Size,
+		Time: l.now(),
+	}
+
+	if !evt.ModTime.IsZero() {
+		entry.ModTime = &evt.ModTime
+	}
+
+	return l.enc.Encode(entry)
+}
+
+// flush flushes the buffer of the log file if one has been opened.
+func (l *eventLog) flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buf == nil {
+		return nil
+	}
+
+	return l.buf.Flush()
+}
+
+// NewLoggingWatcher configures w to write every event it emits to out as a
+// single line of JSON in addition to delivering it to C and any handlers,
+// i.e.
+//
+//	{"type":"created","path":"foo.go","modTime":"2022-11-12T19:28:17Z","size":12,"time":"2022-11-12T19:28:18.123Z"}
+//
+// time is the time the event has been emitted; modTime and size are omitted
+// if zero. Events are written in the order they are emitted. Errors writing
+// to out are reported via the errors channel. NewLoggingWatcher returns w to
+// allow using it in place of w; it must be called before w is started.
+func NewLoggingWatcher(w *Watcher, out io.Writer) *Watcher {
+	w.eventLog = &eventLog{
+		out: out,
+		enc: json.NewEncoder(out),
+		now: time.Now,
+	}
+
+	return w
+}
+
+// openEventLog opens the log file set with WithEventLog.
+func (w *Watcher) openEventLog() error {
+	if w.eventLogFile == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(w.eventLogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+
+	buf := bufio.NewWriter(f)
+
+	if w.eventLog == nil {
+		NewLoggingWatcher(w, buf)
+	} else {
+		// Write to both, the writer passed to NewLoggingWatcher and the file.
+		w.eventLog.enc = json.NewEncoder(io.MultiWriter(w.eventLog.out, buf))
+	}
+
+	w.eventLog.buf = buf
+	w.eventLog.file = f
+
+	return nil
+}
+
+// flushEventLog flushes the log file set with WithEventLog.
+func (w *Watcher) flushEventLog() {
+	if w.eventLog == nil {
+		return
+	}
+
+	if err := w.eventLog.flush(); err != nil {
+		w.reportError(fmt.Errorf("failed to log event: %w", err))
+	}
+}
+
+// closeEventLog flushes and closes the log file set with WithEventLog. The
+// log is reset to the state before openEventLog so that the file can be
+// opened again.
+func (w *Watcher) closeEventLog() {
+	l := w.eventLog
+	if l == nil || l.file == nil {
+		return
+	}
+
+	w.flushEventLog()
+
+	if err := l.file.Close(); err != nil {
+		w.reportError(fmt.Errorf("failed to close event log: %w", err))
+	}
+
+	if l.out == io.Writer(l.buf) {
+		// The log has been created by openEventLog.
+		w.eventLog = nil
+		return
+	}
+
+	l.mu.Lock()
+	l.enc = json.NewEncoder(l.out)
+	l.buf = nil
+	l.file = nil
+	l.mu.Unlock()
+}
+
+// logEvent writes evt to w's event log if one has been configured.
+func (w *Watcher) logEvent(evt Event) {
+	if w.eventLog == nil {
+		return
+	}
+
+	if err := w.eventLog.write(evt); err != nil {
+		w.reportError(fmt.Errorf("failed to log event: %w", err))
+	}
+}
+
+// ReplayEventLog reads all events from the log file path written by a
+// watcher created with WithEventLog. Events are returned in the order they
+// have been logged. Each event carries its type, path, modification time and
+// size; the watcher ID and sequence number are not logged. A partially
+// written last line without a terminating newline, as left by a watcher
+// terminated while writing, is ignored.
+func ReplayEventLog(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+
+	lines := bytes.Split(data, []byte("\n"))
+	for n, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry eventLogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if n == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("invalid event log %s: line %d: %w", path, n+1, err)
+		}
+
+		evt := Event{
+			Type: entry.Type,
+			Path: entry.Path,
+			Size: entry.Size,
+		}
+
+		if entry.ModTime != nil {
+			evt.ModTime = *entry.ModTime
+		}
+
+		events = append(events, evt)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,154 @@
+package globwatch
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestNewLoggingWatcher(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.EmptyFile("util.go"),
+	))
+
+	w, err := New(fsys, "*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	watcher := NewLoggingWatcher(w, &out)
+	ExpectThat(t, watcher == w).Is(Equal(true))
+
+	now := time.Date(2022, 11, 12, 19, 28, 18, 0, time.UTC)
+	watcher.eventLog.now = func() time.Time { return now }
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("tool.go")
+	fsys.Touch("main.go")
+	fsys.Rm("util.go")
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+	modTime := func(name string) string {
+		i, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return i.ModTime().Format(time.RFC3339Nano)
+	}
+
+	ExpectThat(t, out.String()).Is(Equal(
+		`{"type":"created","path":"tool.go","modTime":"` + modTime("tool.go") + `","time":"2022-11-12T19:28:18Z"}` + "\n" +
+			`{"type":"modified","path":"main.go","modTime":"` + modTime("main.go") + `","time":"2022-11-12T19:28:18Z"}` + "\n" +
+			`{"type":"deleted","path":"util.go","time":"2022-11-12T19:28:18Z"}` + "\n",
+	))
+
+	close(watcher.c)
+
+	evts := make([]Event, 0, 3)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "tool.go"},
+		{Type: Modified, Path: "main.go"},
+		{Type: Deleted, Path: "util.go"},
+	}))
+}
+
+func TestWatcher_WithEventLog(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	var received []Event
+	for i := 0; i < 2; i++ {
+		// Starting a second watcher appends to the log.
+		watcher, err := New(fsys, "*.go", time.Millisecond, WithEventLog(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := watcher.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		fsys.Touch("main.go")
+		received = append(received, <-watcher.C())
+
+		watcher.Close()
+	}
+
+	replayed, err := ReplayEventLog(path)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(replayed)).Is(Equal(len(received)))
+
+	for i, evt := range replayed {
+		ExpectThat(t, evt.Type).Is(Equal(received[i].Type))
+		ExpectThat(t, evt.Path).Is(Equal(received[i].Path))
+		ExpectThat(t, evt.Size).Is(Equal(received[i].Size))
+		ExpectThat(t, evt.ModTime.Equal(received[i].ModTime)).Is(Equal(true))
+	}
+}
+
+func TestWatcher_WithEventLog_invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	watcher, err := New(fsmock.New(fsmock.NewDir("")), "*.go", time.Millisecond,
+		WithEventLog(filepath.Join(dir, "missing", "events.log")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Start()).Is(Error(fs.ErrNotExist))
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(false))
+
+	path := filepath.Join(dir, "events.log")
+	if err := os.WriteFile(path, []byte(`{"type":"created","path":"main.go","time":"2022-11-12T19:28:18Z"}`+"\ninvalid\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReplayEventLog(path)
+	ExpectThat(t, err.Error()).Is(StringContaining("line 2"))
+
+	_, err = ReplayEventLog(filepath.Join(dir, "missing.log"))
+	ExpectThat(t, err).Is(Error(fs.ErrNotExist))
+}
+
+func TestReplayEventLog_truncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	entry := `{"type":"created","path":"main.go","time":"2022-11-12T19:28:18Z"}`
+
+	if err := os.WriteFile(path, []byte(entry+"\n"+entry[:20]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := ReplayEventLog(path)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(replayed)).Is(Equal(1))
+	ExpectThat(t, replayed[0].Path).Is(Equal("main.go"))
+
+	if err := os.WriteFile(path, []byte(entry[:20]+"\n"+entry+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReplayEventLog(path)
+	ExpectThat(t, err.Error()).Is(StringContaining("line 1"))
+}
@@ -0,0 +1,44 @@
+// Package events provides combinators transforming the events received from
+// a globwatch.Watcher before they are consumed.
+//
+// All combinators read from a channel in a dedicated goroutine and close the
+// returned channel once the input channel has been closed.
+package events
+
+import "github.com/halimath/globwatch"
+
+// Map returns a channel receiving the result of applying fn to each event
+// received from c. Use it to convert or enrich events, i.e. to attach a
+// checksum of the file's content.
+func Map(c <-chan globwatch.Event, fn func(globwatch.Event) globwatch.Event) <-chan globwatch.Event {
+	out := make(chan globwatch.Event)
+
+	go func() {
+		defer close(out)
+
+		for evt := range c {
+			out <- fn(evt)
+		}
+	}()
+
+	return out
+}
+
+// FlatMap returns a channel receiving all events returned from fn for each
+// event received from c in order. fn may return any number of events
+// including none, so FlatMap can be used to fan out or to drop events.
+func FlatMap(c <-chan globwatch.Event, fn func(globwatch.Event) []globwatch.Event) <-chan globwatch.Event {
+	out := make(chan globwatch.Event)
+
+	go func() {
+		defer close(out)
+
+		for evt := range c {
+			for _, e := range fn(evt) {
+				out <- e
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func source(evts ...globwatch.Event) <-chan globwatch.Event {
+	c := make(chan globwatch.Event, len(evts))
+	for _, evt := range evts {
+		c <- evt
+	}
+	close(c)
+	return c
+}
+
+func collect(c <-chan globwatch.Event) []globwatch.Event {
+	var evts []globwatch.Event
+	for evt := range c {
+		evts = append(evts, evt)
+	}
+	return evts
+}
+
+func TestMap(t *testing.T) {
+	c := source(
+		globwatch.Event{Type: globwatch.Created, Path: "cmd/main.go"},
+		globwatch.Event{Type: globwatch.Deleted, Path: "go.mod"},
+	)
+
+	got := collect(Map(c, func(evt globwatch.Event) globwatch.Event {
+		return globwatch.Event{Type: evt.Type, Path: strings.ToUpper(evt.Path)}
+	}))
+
+	ExpectThat(t, got).Is(DeepEqual([]globwatch.Event{
+		{Type: globwatch.Created, Path: "CMD/MAIN.GO"},
+		{Type: globwatch.Deleted, Path: "GO.MOD"},
+	}))
+}
+
+func TestFlatMap(t *testing.T) {
+	c := source(
+		globwatch.Event{Type: globwatch.Created, Path: "main.go"},
+		globwatch.Event{Type: globwatch.Modified, Path: "tool.go"},
+		globwatch.Event{Type: globwatch.Deleted, Path: "README.md"},
+	)
+
+	got := collect(FlatMap(c, func(evt globwatch.Event) []globwatch.Event {
+		if evt.Type == globwatch.Deleted {
+			return nil
+		}
+		return []globwatch.Event{evt, {Type: evt.Type, Path: evt.Path + ".bak"}}
+	}))
+
+	ExpectThat(t, got).Is(DeepEqual([]globwatch.Event{
+		{Type: globwatch.Created, Path: "main.go"},
+		{Type: globwatch.Created, Path: "main.go.bak"},
+		{Type: globwatch.Modified, Path: "tool.go"},
+		{Type: globwatch.Modified, Path: "tool.go.bak"},
+	}))
+}
@@ -0,0 +1,69 @@
+package globwatch
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/halimath/globwatch/pattern"
+)
+
+// EventFilter defines a predicate deciding whether an event is forwarded by
+// Filter.
+type EventFilter func(Event) bool
+
+// Filter returns a channel receiving all events received from c for which
+// all of filters return true. Filters are evaluated in order; evaluation
+// stops with the first filter returning false. The returned channel is
+// closed once c has been closed. Use it to narrow down the events received
+// from a Watcher, i.e.
+//
+//	events := globwatch.Filter(watcher.C(), globwatch.FilterType(globwatch.Created), globwatch.FilterPathPrefix("cmd/"))
+func Filter(c <-chan Event, filters ...EventFilter) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+
+	events:
+		for evt := range c {
+			for _, f := range filters {
+				if !f(evt) {
+					continue events
+				}
+			}
+
+			out <- evt
+		}
+	}()
+
+	return out
+}
+
+// FilterType returns an EventFilter accepting events of any of types.
+func FilterType(types ...EventType) EventFilter {
+	return func(evt Event) bool {
+		return slices.Contains(types, evt.Type)
+	}
+}
+
+// FilterPath returns an EventFilter accepting events whose path matches pat.
+func FilterPath(pat *pattern.Pattern) EventFilter {
+	return func(evt Event) bool {
+		return pat.Match(evt.Path)
+	}
+}
+
+// FilterPathPrefix returns an EventFilter accepting events whose path starts
+// with prefix.
+func FilterPathPrefix(prefix string) EventFilter {
+	return func(evt Event) bool {
+		return strings.HasPrefix(evt.Path, prefix)
+	}
+}
+
+// FilterNot returns an EventFilter accepting all events rejected by f.
+func FilterNot(f EventFilter) EventFilter {
+	return func(evt Event) bool {
+		return !f(evt)
+	}
+}
@@ -0,0 +1,48 @@
+package globwatch_test
+
+import (
+	"testing"
+
+	"github.com/halimath/globwatch"
+	"github.com/halimath/globwatch/pattern"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestFilter(t *testing.T) {
+	c := make(chan globwatch.Event, 5)
+	c <- globwatch.Event{Type: globwatch.Created, Path: "cmd/main.go"}
+	c <- globwatch.Event{Type: globwatch.Modified, Path: "cmd/main.go"}
+	c <- globwatch.Event{Type: globwatch.Created, Path: "cmd/main_test.go"}
+	c <- globwatch.Event{Type: globwatch.Deleted, Path: "cmd/tool.go"}
+	c <- globwatch.Event{Type: globwatch.Created, Path: "README.md"}
+	close(c)
+
+	filtered := globwatch.Filter(c,
+		globwatch.FilterType(globwatch.Created, globwatch.Deleted),
+		globwatch.FilterNot(globwatch.FilterPath(pattern.MustNew("**/*_test.go"))),
+		globwatch.FilterPathPrefix("cmd/"),
+	)
+
+	var got []globwatch.Event
+	for evt := range filtered {
+		got = append(got, evt)
+	}
+
+	ExpectThat(t, got).Is(DeepEqual([]globwatch.Event{
+		{Type: globwatch.Created, Path: "cmd/main.go"},
+		{Type: globwatch.Deleted, Path: "cmd/tool.go"},
+	}))
+}
+
+func TestFilter_noFilters(t *testing.T) {
+	c := make(chan globwatch.Event, 1)
+	c <- globwatch.Event{Type: globwatch.Created, Path: "main.go"}
+	close(c)
+
+	filtered := globwatch.Filter(c)
+	ExpectThat(t, <-filtered).Is(DeepEqual(globwatch.Event{Type: globwatch.Created, Path: "main.go"}))
+
+	_, ok := <-filtered
+	ExpectThat(t, ok).Is(Equal(false))
+}
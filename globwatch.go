@@ -12,14 +12,71 @@
 package globwatch
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"io/fs"
+	"iter"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/halimath/globwatch/pattern"
 )
 
+var (
+	// ErrAlreadyStarted is returned when starting a Watcher that is already
+	// running.
+	ErrAlreadyStarted = errors.New("watcher already started")
+
+	// ErrClosed is returned when operating on a Watcher that is not running
+	// or is being closed.
+	ErrClosed = errors.New("watcher closed")
+
+	// ErrHandlerPanic is reported when a handler registered via OnEvent
+	// panics.
+	ErrHandlerPanic = errors.New("handler panicked")
+
+	// ErrEventDropped is reported via the errors channel when an event could
+	// not be delivered to a subscriber because its channel was full.
+	ErrEventDropped = errors.New("event dropped")
+
+	// ErrNotFound is returned when removing a filesystem from a Pool or a
+	// name from a WatchList that has not been added.
+	ErrNotFound = errors.New("not found")
+
+	// ErrDuplicate is returned when adding a filesystem and pattern to a
+	// Pool or a name to a WatchList that has already been added.
+	ErrDuplicate = errors.New("duplicate")
+
+	// ErrNoJournal is returned from ReplayFrom if no journal has been enabled
+	// using WithJournal.
+	ErrNoJournal = errors.New("no journal")
+
+	// ErrInvalidOption is returned from New when an option has been given an
+	// invalid value.
+	ErrInvalidOption = errors.New("invalid option")
+
+	// ErrNotClosed is returned when calling Drain on a Watcher that has not
+	// been closed.
+	ErrNotClosed = errors.New("watcher not closed")
+
+	// ErrMaxErrorsExceeded is reported when a Watcher stops after the number
+	// of consecutive errors set with WithMaxConsecutiveErrors.
+	ErrMaxErrorsExceeded = errors.New("maximum number of consecutive errors exceeded")
+
+	// ErrSymlinkCycle is reported when a symlink is not followed because it
+	// points to a directory containing the symlink or because the maximum
+	// symlink depth has been exceeded.
+	ErrSymlinkCycle = errors.New("symlink cycle")
+)
+
 // EventType defines the type of event for a changed file.
 type EventType int
 
@@ -30,6 +87,14 @@ const (
 	Modified
 	// Deleted reports that an existing file has been deleted (or moved away).
 	Deleted
+	// Truncated reports that an existing file has changed and its size
+	// decreased. Truncated is only reported if enabled with
+	// WithTruncationDetection; Modified is reported otherwise.
+	Truncated
+	// Heartbeat reports that a poll cycle has completed without detecting
+	// any change. Heartbeat events carry an empty path and are only sent to
+	// C if enabled with WithHeartbeat.
+	Heartbeat
 )
 
 // String returns a string representation of t.
@@ -41,6 +106,10 @@ func (t EventType) String() string {
 		return "modified"
 	case Deleted:
 		return "deleted"
+	case Truncated:
+		return "truncated"
+	case Heartbeat:
+		return "heartbeat"
 	default:
 		return "unknown"
 	}
@@ -52,6 +121,30 @@ type Event struct {
 	Type EventType
 	// The full path of the file relative to the watched root
 	Path string
+	// The file's modification time at the time the event was detected. Zero
+	// for Deleted events.
+	ModTime time.Time
+	// The file's size in bytes at the time the event was detected. Zero for
+	// Deleted events.
+	Size int64
+	// The filesystem the file belongs to if it has been added using AddRoot;
+	// nil for files of the watcher's own filesystem
+	Root fs.FS
+	// The ID of the watcher that emitted the event (see Watcher.ID)
+	WatcherID string
+	// The event's sequence number. Each watcher numbers the events it emits
+	// starting with 1.
+	SeqNum uint64
+}
+
+// String returns a string representation of e containing its type and path.
+// If e has been emitted by a watcher the watcher's ID and the event's
+// sequence number are appended.
+func (e Event) String() string {
+	if e.WatcherID == "" {
+		return fmt.Sprintf("%s: %s", e.Type, e.Path)
+	}
+	return fmt.Sprintf("%s: %s [%s#%d]", e.Type, e.Path, e.WatcherID, e.SeqNum)
 }
 
 // Watcher implements glob watching. Events for changed files will be reported
@@ -60,37 +153,281 @@ type Event struct {
 // detection otherwise.
 type Watcher struct {
 	fsys     fs.FS
-	pat      *pattern.Pattern
+	absDir   string
+	pat      atomic.Pointer[pattern.Pattern]
 	interval time.Duration
+	mu       sync.RWMutex
 	modtimes map[string]time.Time
+	watched  map[string]struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	running  atomic.Bool
+	started  atomic.Bool
+	closing  atomic.Bool
+	scanMu   sync.Mutex
 	close    chan struct{}
 	closed   chan struct{}
 	errors   chan error
 	c        chan Event
+	subsMu   sync.RWMutex
+	subs     []*subscriber
+	subsDone bool
+
+	cSub            *subscriber
+	eventBufferSize int
+	eventQueueSize  int
+	errorBufferSize int
+	channelPolicy   ChannelPolicy
+	channelDrops    atomic.Uint64
+
+	callbackWorkers int
+	callbacksOnly   bool
+	callbacks       chan func()
+	callbackWG      sync.WaitGroup
+	handlersMu      sync.RWMutex
+	eventHandlers   []func(Event)
+	createHandlers  []func(Event)
+	modifyHandlers  []func(Event)
+	deleteHandlers  []func(Event)
+	errorHandlers   []func(error)
+
+	logger *slog.Logger
+
+	pollMu      sync.Mutex
+	pollWaiters []chan struct{}
+	pollDone    bool
+
+	incremental     bool
+	followSymlinks  bool
+	maxSymlinkDepth int
+	shadow          map[string]*shadowDir
+
+	telemetry Telemetry
+	eventLog  *eventLog
+
+	eventLogFile string
+
+	// Watcher for the config file if created using NewFromConfig
+	config *Watcher
+
+	rootsMu      sync.Mutex
+	roots        []*root
+	rootsCtx     context.Context
+	rootsStarted bool
+	rootsDone    bool
+
+	checkpointFile     string
+	checkpointInterval time.Duration
+	checkpointDone     chan struct{}
+	checkpointWG       sync.WaitGroup
+	restored           bool
+
+	manifest io.Reader
+
+	gracefulClose bool
+
+	deduplication bool
+	seenModified  map[string]time.Time
+
+	intervals  map[string]time.Duration
+	groups     []intervalGroup
+	groupsDone chan struct{}
+	groupsWG   sync.WaitGroup
+
+	heartbeat bool
+
+	walkDepth         int
+	autoDiscoverDepth int
+	discovery         dirDiscovery
+
+	journalCapacity int
+	journal         *journal
+
+	id     string
+	seqNum atomic.Uint64
+
+	healthMu         sync.Mutex
+	lastPollAt       time.Time
+	lastPollDuration time.Duration
+	errorTimes       []time.Time
+
+	initialEvents bool
+
+	fatalError  func(error) bool
+	maxFailures int
+	failures    int
+
+	backoffBase     time.Duration
+	backoffMax      time.Duration
+	backoffInterval atomic.Int64
+
+	coalesceWindow time.Duration
+	coalesceMu     sync.Mutex
+	pending        []Event
+	pendingIndex   map[string]int
+	coalesceTimer  *time.Timer
+
+	truncation bool
+	filesizes  map[string]int64
+
+	newHash   func() hash.Hash
+	checksums map[string][]byte
+
+	limiter       *tokenBucket
+	dropHandler   func(Event)
+	droppedEvents atomic.Uint64
 }
 
 // New creates a new watcher. The watcher will use fsys to access the files
 // and directories. It will use fsys as the root to watch. pat defines the
 // pattern relative to fsys' root. interval defines how often to check for
-// changes.
+// changes. opts can be used to further customize the watcher.
 // A created watcher will not start watching for changes unless Start or
 // StartContext is called.
-func New(fsys fs.FS, pat string, interval time.Duration) (*Watcher, error) {
+func New(fsys fs.FS, pat string, interval time.Duration, opts ...Option) (*Watcher, error) {
 	p, err := pattern.New(pat)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Watcher{
+	w := &Watcher{
 		modtimes: make(map[string]time.Time),
+		watched:  make(map[string]struct{}),
 		fsys:     fsys,
-		pat:      p,
 		interval: interval,
 		close:    make(chan struct{}),
 		closed:   make(chan struct{}),
-		errors:   make(chan error, 10),
-		c:        make(chan Event, 10),
-	}, nil
+
+		eventBufferSize: 10,
+		errorBufferSize: 10,
+		telemetry:       NopTelemetry{},
+
+		maxSymlinkDepth: defaultMaxSymlinkDepth,
+
+		callbackWorkers: 1,
+	}
+
+	w.pat.Store(p)
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.eventBufferSize < 0 {
+		return nil, fmt.Errorf("%w: negative event buffer size: %d", ErrInvalidOption, w.eventBufferSize)
+	}
+
+	if w.coalesceWindow > 0 {
+		w.pendingIndex = make(map[string]int)
+	}
+
+	if w.eventQueueSize < 0 {
+		return nil, fmt.Errorf("%w: negative event queue size: %d", ErrInvalidOption, w.eventQueueSize)
+	}
+
+	if w.maxSymlinkDepth < 0 {
+		return nil, fmt.Errorf("%w: negative max symlink depth: %d", ErrInvalidOption, w.maxSymlinkDepth)
+	}
+
+	if w.errorBufferSize < 0 {
+		return nil, fmt.Errorf("%w: negative error buffer size: %d", ErrInvalidOption, w.errorBufferSize)
+	}
+
+	if w.limiter != nil && w.limiter.capacity <= 0 {
+		return nil, fmt.Errorf("%w: non-positive event rate limit: %v", ErrInvalidOption, w.limiter.capacity)
+	}
+	if w.limiter != nil && w.limiter.period <= 0 {
+		return nil, fmt.Errorf("%w: non-positive event rate limit period: %s", ErrInvalidOption, w.limiter.period)
+	}
+
+	if w.backoffBase < 0 {
+		return nil, fmt.Errorf("%w: negative error backoff base: %s", ErrInvalidOption, w.backoffBase)
+	}
+	if w.backoffBase > 0 && w.backoffMax <= 0 {
+		return nil, fmt.Errorf("%w: non-positive error backoff max: %s", ErrInvalidOption, w.backoffMax)
+	}
+	if w.backoffMax < w.backoffBase {
+		return nil, fmt.Errorf("%w: error backoff max %s less than base %s", ErrInvalidOption, w.backoffMax, w.backoffBase)
+	}
+
+	if len(w.intervals) > 0 {
+		w.groups, err = newIntervalGroups(w.intervals)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if w.walkDepth < 0 {
+		return nil, fmt.Errorf("%w: negative walk depth: %d", ErrInvalidOption, w.walkDepth)
+	}
+
+	if w.deduplication {
+		w.seenModified = make(map[string]time.Time)
+	}
+
+	if w.autoDiscoverDepth < 0 {
+		return nil, fmt.Errorf("%w: negative auto discover depth: %d", ErrInvalidOption, w.autoDiscoverDepth)
+	}
+
+	if w.journalCapacity < 0 {
+		return nil, fmt.Errorf("%w: negative journal capacity: %d", ErrInvalidOption, w.journalCapacity)
+	}
+	if w.journalCapacity > 0 {
+		w.journal = newJournal(w.journalCapacity)
+	}
+
+	if w.id == "" {
+		w.id, err = newWatcherID()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if w.checkpointFile != "" && w.checkpointInterval <= 0 {
+		return nil, fmt.Errorf("%w: non-positive checkpoint interval: %s", ErrInvalidOption, w.checkpointInterval)
+	}
+
+	if w.telemetry == nil {
+		w.telemetry = NopTelemetry{}
+	}
+
+	w.c = make(chan Event, w.eventBufferSize)
+	w.errors = make(chan error, w.errorBufferSize)
+
+	if w.truncation {
+		w.filesizes = make(map[string]int64)
+	}
+
+	if w.newHash != nil {
+		w.checksums = make(map[string][]byte)
+	}
+
+	// C is the first subscriber. Unless configured otherwise, sending to C
+	// blocks instead of dropping events.
+	w.cSub = &subscriber{c: w.c, policy: w.channelPolicy}
+	if w.eventQueueSize > 0 {
+		w.cSub.q = newEventQueue(w.eventQueueSize, w.channelPolicy)
+	}
+	w.subs = []*subscriber{w.cSub}
+
+	return w, nil
+}
+
+// NewWithContext creates a new watcher just like New and starts it using
+// ctx (see StartContext). The returned watcher is already running. It is
+// closed automatically once ctx is done, so calling Close is not required.
+// NewWithContext returns any error returned from New or StartContext.
+func NewWithContext(ctx context.Context, fsys fs.FS, pat string, interval time.Duration, opts ...Option) (*Watcher, error) {
+	w, err := New(fsys, pat, interval, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.StartContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return w, nil
 }
 
 // C returns a channel used to receive change Events.
@@ -98,6 +435,21 @@ func (w *Watcher) C() <-chan Event {
 	return w.c
 }
 
+// Events returns an iterator yielding all events received from C. The
+// iterator blocks waiting for the next event and stops once w has been
+// closed, either by calling Close or by canceling the context passed to
+// StartContext. Events and C share the same underlying channel so each event
+// is delivered to only one of them.
+func (w *Watcher) Events() iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		for evt := range w.c {
+			if !yield(evt) {
+				return
+			}
+		}
+	}
+}
+
 // ErrorsChan returns a channel used to receive errors during watching.
 func (w *Watcher) ErrorsChan() <-chan error {
 	return w.errors
@@ -110,105 +462,616 @@ func (w *Watcher) Start() error {
 
 // StartContext starts watching for changes. If ctx will be canceled w will
 // be closed. The funtion reports any error that occured during initial
-// file analysis.
+// file analysis. Starting a running watcher returns ErrAlreadyStarted;
+// starting a watcher that has been closed returns ErrClosed.
 func (w *Watcher) StartContext(ctx context.Context) error {
-	if err := w.determineInitialState(); err != nil {
+	if w.closing.Load() {
+		return ErrClosed
+	}
+
+	if !w.running.CompareAndSwap(false, true) {
+		return ErrAlreadyStarted
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.ctx = ctx
+
+	w.startCallbackWorkers()
+
+	if err := w.openEventLog(); err != nil {
+		w.cancel()
+		w.stopCallbackWorkers()
+		w.running.Store(false)
 		return err
 	}
 
-	ticker := time.NewTicker(w.interval)
+	var initial []Event
+	err := w.loadCheckpointFile()
+	if err == nil {
+		w.loadManifest()
+	}
+	if err == nil && !w.restored {
+		initial, err = w.determineInitialState(ctx)
+	}
+	if err != nil {
+		w.closeEventLog()
+		w.cancel()
+		w.stopCallbackWorkers()
+		w.running.Store(false)
+		return err
+	}
+
+	if err := w.startConfigWatcher(ctx); err != nil {
+		w.closeEventLog()
+		w.cancel()
+		w.stopCallbackWorkers()
+		w.running.Store(false)
+		return err
+	}
 
+	if err := w.startRoots(ctx); err != nil {
+		w.stopConfigWatcher()
+		w.closeEventLog()
+		w.cancel()
+		w.stopCallbackWorkers()
+		w.running.Store(false)
+		return err
+	}
+
+	w.cSub.startForwarding()
+	w.startIntervalGroups(ctx)
+	w.startCheckpointing()
+
+	w.started.Store(true)
 	go func() {
-		defer ticker.Stop()
-		defer close(w.c)
-		defer close(w.errors)
 		defer close(w.closed)
+		defer w.running.Store(false)
+		defer w.cancel()
+		defer w.closeSubscribers()
+		defer w.closePollWaiters()
+		defer close(w.errors)
+		defer w.stopCallbackWorkers()
+		defer w.stopCheckpointing()
+		defer w.closeEventLog()
+		defer w.awaitScans()
+		defer w.stopConfigWatcher()
+		defer w.stopIntervalGroups()
+		defer w.stopRoots()
 
-		for {
-			select {
-			case <-ticker.C:
-				w.detectChanges()
-			case <-w.close:
-				return
-			case <-ctx.Done():
+		if w.initialEvents {
+			for _, evt := range initial {
+				w.emit(evt)
+			}
+		}
+
+		if w.restored {
+			// Report all changes made since the checkpoint or manifest has
+			// been saved.
+			if w.failed(w.scan(ctx)) {
 				return
 			}
 		}
+
+		w.run(ctx)
 	}()
 
 	return nil
 }
 
+// poll detects changes every interval until w is closed or ctx is done.
+func (w *Watcher) poll(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if w.failed(w.scan(ctx)) {
+				return
+			}
+			ticker.Reset(w.nextInterval())
+		case <-w.close:
+			w.closeGracefully(ctx)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// closeGracefully performs a final change detection when w is being closed
+// and graceful close has been enabled using WithGracefulClose.
+func (w *Watcher) closeGracefully(ctx context.Context) {
+	if w.gracefulClose {
+		w.scan(ctx)
+	}
+}
+
+// scan performs a single change detection serialized with Reset.
+func (w *Watcher) scan(ctx context.Context) error {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	return w.detectChanges(ctx)
+}
+
+// DetectChanges performs a single change detection synchronously and emits
+// the events found to C, subscribers and event handlers just like a regular
+// poll. Use it to trigger a scan in response to an external signal. It is
+// serialized with the polling performed while w is running as well as with
+// Reset.
+//
+// DetectChanges may be called without starting w. In that case the first
+// call reports all matching files as Created. The callback workers and the
+// event log set with WithEventLog are started for the duration of the scan,
+// so DetectChanges returns once all handlers have been invoked. Calls made
+// before w is started must not run concurrently with Start. As events are
+// sent to C according to the policy set with WithFullChannelPolicy, a caller
+// not reading from C may block once C's buffer is full.
+//
+// DetectChanges returns ErrClosed if w is being closed or the error that
+// caused the scan to fail.
+func (w *Watcher) DetectChanges() error {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	if w.closing.Load() {
+		return ErrClosed
+	}
+
+	if w.running.Load() {
+		return w.detectChanges(w.ctx)
+	}
+
+	w.startCallbackWorkers()
+	defer w.stopCallbackWorkers()
+
+	if err := w.openEventLog(); err != nil {
+		return err
+	}
+	defer w.closeEventLog()
+
+	err := w.detectChanges(context.Background())
+
+	// Emit coalesced events before the callback workers and the event log
+	// are shut down.
+	w.flushPending()
+
+	return err
+}
+
+// failed records the result of a change detection that returned err. It
+// reports whether w should stop watching because the number of consecutive
+// failures set with WithMaxConsecutiveErrors has been reached.
+func (w *Watcher) failed(err error) bool {
+	if err == nil || w.ctx.Err() != nil {
+		w.failures = 0
+		return false
+	}
+
+	w.failures++
+
+	if w.maxFailures > 0 && w.failures >= w.maxFailures {
+		w.log(slog.LevelError, "stopping after consecutive errors", slog.Int("errors", w.failures))
+		w.reportError(fmt.Errorf("%w: %w", ErrMaxErrorsExceeded, err))
+		return true
+	}
+
+	return false
+}
+
+// IsRunning reports whether w has been started and is still watching for
+// changes.
+func (w *Watcher) IsRunning() bool {
+	return w.running.Load()
+}
+
 // Close closes w. The change detection goroutine will be shutdown gracefully
-// and both w.C and w.Errors will be closed before Close returns.
+// and both w.C and w.Errors will be closed before Close returns. Any directory
+// walk in progress is canceled unless graceful close has been enabled using
+// WithGracefulClose. Closing a watcher that has never been started successfully
+// returns immediately.
 func (w *Watcher) Close() {
+	w.closing.Store(true)
 	close(w.close)
+	if !w.started.Load() {
+		return
+	}
+	if !w.gracefulClose {
+		w.cancel()
+	}
 	<-w.closed
 }
 
-func (w *Watcher) determineInitialState() error {
-	names, err := w.pat.GlobFS(w.fsys, ".")
+// currentPattern returns the pattern used to match files.
+func (w *Watcher) currentPattern() *pattern.Pattern {
+	return w.pat.Load()
+}
+
+// Files returns the sorted paths of all files currently tracked by w. The
+// returned slice is a snapshot and is safe to use while w is running.
+func (w *Watcher) Files() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	files := make([]string, 0, len(w.modtimes))
+	for name := range w.modtimes {
+		files = append(files, w.externalPath(name))
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+// Count returns the number of files currently tracked by w.
+func (w *Watcher) Count() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return len(w.modtimes)
+}
+
+// Watch adds the file at path to the set of files tracked by w. path is
+// relative to the watched fsys' root. The file is tracked independently of
+// w's pattern so Modified and Deleted events are reported for it even if it
+// does not match the pattern. If the file is deleted, it is still watched and
+// a Created event will be reported once it reappears.
+// Watch returns an error if the file cannot be stat'ed. It is safe to call
+// Watch while w is running.
+func (w *Watcher) Watch(path string) error {
+	i, err := fs.Stat(w.fsys, path)
 	if err != nil {
-		return fmt.Errorf("failed to detect watcher: %w", err)
+		return err
 	}
 
-	for _, name := range names {
-		i, err := fs.Stat(w.fsys, name)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.watched[path] = struct{}{}
+
+	return w.track(path, i)
+}
+
+// Unwatch removes path from the set of files tracked via Watch. No further
+// events are reported for path unless it matches w's pattern. It is safe to
+// call Unwatch while w is running.
+func (w *Watcher) Unwatch(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.watched[path]; !ok {
+		return
+	}
+
+	delete(w.watched, path)
+	if !w.currentPattern().Match(path) {
+		w.untrack(path)
+	}
+}
+
+// determineInitialState records the state of all files matching w's
+// pattern. It returns a Created event for each file found.
+func (w *Watcher) determineInitialState(ctx context.Context) ([]Event, error) {
+	start := time.Now()
+
+	entries, werrs, err := w.glob(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect watcher: %w", err)
+	}
+	defer w.recordPoll(start)
+
+	var events []Event
+	errs := werrs.errs
+
+	w.mu.Lock()
+	for _, e := range entries {
+		i, err := e.Info()
 		if err != nil {
-			w.errors <- err
+			errs = append(errs, err)
 			continue
 		}
-		w.modtimes[name] = i.ModTime()
+		if err := w.track(e.Path, i); err != nil {
+			errs = append(errs, err)
+		}
+
+		events = append(events, Event{
+			Type:    Created,
+			Path:    e.Path,
+			ModTime: i.ModTime(),
+			Size:    i.Size(),
+		})
+	}
+	w.mu.Unlock()
+
+	for _, err := range errs {
+		w.reportError(err)
 	}
 
+	return events, nil
+}
+
+// detectChanges performs a single poll cycle. It returns any error that
+// prevented the cycle from completing.
+func (w *Watcher) detectChanges(ctx context.Context) error {
+	start := time.Now()
+
+	waiters := w.beginPoll()
+	success := false
+	defer func() {
+		if success {
+			w.recordPoll(start)
+		}
+		w.endPoll(waiters, success)
+	}()
+
+	var events []Event
+	scanned, errs, err := w.detectChangesInto(ctx, false, func(evt Event) {
+		events = append(events, evt)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			// The watcher is shutting down; the walk has been canceled.
+			return ctx.Err()
+		}
+
+		err = fmt.Errorf("failed to detect changes: %w", err)
+		w.log(slog.LevelError, "failed to walk directory", slog.Any("error", err))
+		w.reportError(err)
+		w.telemetry.RecordErrors(1)
+		return err
+	}
+
+	for _, err := range errs {
+		w.reportError(err)
+	}
+
+	w.publish(events)
+	w.flushEventLog()
+
+	if w.heartbeat && len(events) == 0 {
+		w.sendHeartbeat()
+	}
+
+	success = true
+
+	w.telemetry.RecordPollDuration(time.Since(start))
+	w.telemetry.RecordFilesScanned(scanned)
+	w.telemetry.RecordEventsEmitted(len(events))
+	w.telemetry.RecordErrors(len(errs))
+
+	w.log(slog.LevelDebug, "poll completed",
+		slog.Duration("poll_duration", time.Since(start)),
+		slog.Int("files_scanned", scanned),
+		slog.Int("events_emitted", len(events)),
+	)
+
 	return nil
 }
 
-func (w *Watcher) detectChanges() {
-	names, err := w.pat.GlobFS(w.fsys, ".")
+// detectChangesInto walks w's filesystem, compares the files found with the
+// tracked files and passes the resulting events to emit in the order
+// defined by sortEvents. Unless dryRun is set, the tracked files are
+// updated. It returns the number of files scanned and all non-fatal errors.
+// A non-nil error indicates that the walk failed.
+func (w *Watcher) detectChangesInto(ctx context.Context, dryRun bool, emit func(Event)) (int, []error, error) {
+	entries, werrs, err := w.glob(ctx, dryRun)
 	if err != nil {
-		w.errors <- fmt.Errorf("failed to detect changes: %w", err)
-		return
+		return 0, nil, err
 	}
 
+	// Events and errors are collected while holding the lock and sent
+	// afterwards so that a slow consumer does not block readers of modtimes.
+	var events []Event
+	errs := werrs.errs
+
 	foundNames := make(map[string]struct{})
 
-	for _, name := range names {
-		foundNames[name] = struct{}{}
+	w.mu.Lock()
+	for name := range w.watched {
+		if w.currentPattern().Match(name) {
+			continue
+		}
 
 		i, err := fs.Stat(w.fsys, name)
 		if err != nil {
-			w.errors <- err
+			if !errors.Is(err, fs.ErrNotExist) {
+				foundNames[name] = struct{}{}
+				errs = append(errs, err)
+			}
+
+			// An explicitly watched file is missing. Handle it like a file no
+			// longer matched by the pattern.
 			continue
 		}
 
-		got, ok := w.modtimes[name]
-		if !ok {
-			w.modtimes[name] = i.ModTime()
-			w.c <- Event{
-				Type: Created,
-				Path: name,
-			}
+		entries = append(entries, pattern.Entry{Path: name, DirEntry: fs.FileInfoToDirEntry(i)})
+	}
 
+	for _, e := range entries {
+		name := e.Path
+		foundNames[name] = struct{}{}
+
+		i, err := e.Info()
+		if err != nil {
+			errs = append(errs, err)
 			continue
 		}
 
-		if i.ModTime().After(got) {
-			w.modtimes[name] = i.ModTime()
-			w.c <- Event{
-				Type: Modified,
-				Path: name,
+		if _, ok := w.modtimes[name]; !ok {
+			if !dryRun {
+				if err := w.track(name, i); err != nil {
+					errs = append(errs, err)
+				}
 			}
+			events = append(events, Event{
+				Type:    Created,
+				Path:    name,
+				ModTime: i.ModTime(),
+				Size:    i.Size(),
+			})
+
+			continue
+		}
+
+		evt, changed, err := w.compare(name, i, !dryRun)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if changed {
+			events = append(events, evt)
 		}
 	}
 
 	for n := range w.modtimes {
-		if _, ok := foundNames[n]; !ok {
-			delete(w.modtimes, n)
-			w.c <- Event{
+		if _, ok := foundNames[n]; !ok && !werrs.skipped(n) {
+			if !dryRun {
+				w.untrack(n)
+			}
+			events = append(events, Event{
 				Type: Deleted,
 				Path: n,
-			}
+			})
+		}
+	}
+	w.mu.Unlock()
+
+	sortEvents(events)
+	for _, evt := range events {
+		emit(evt)
+	}
+
+	return len(entries), errs, nil
+}
+
+// eventOrder defines the order of event types reported by a single change
+// detection.
+var eventOrder = map[EventType]int{
+	Created:   1,
+	Modified:  2,
+	Truncated: 3,
+	Deleted:   4,
+}
+
+// sortEvents sorts the events found by a single change detection so that
+// they are reported in a stable order: Created events first, followed by
+// Modified, Truncated and Deleted events. Events of the same type are sorted
+// by path.
+func sortEvents(events []Event) {
+	slices.SortFunc(events, func(a, b Event) int {
+		if c := cmp.Compare(eventOrder[a.Type], eventOrder[b.Type]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+}
+
+// compare compares the state recorded for the tracked file name with i and
+// records i's state if commit is set. It reports whether the file changed
+// along with the event describing the change. It must be called with mu
+// being held.
+func (w *Watcher) compare(name string, i fs.FileInfo, commit bool) (Event, bool, error) {
+	modified := i.ModTime().After(w.modtimes[name])
+	if w.newHash != nil && (modified || i.ModTime().IsZero()) {
+		// The modification time indicates a change or is not available.
+		// Compare the file's content to find out if it actually changed.
+		if commit {
+			w.modtimes[name] = i.ModTime()
 		}
+
+		var err error
+		modified, err = w.updateChecksum(name, commit)
+		if err != nil {
+			return Event{}, false, err
+		}
+	}
+
+	if !modified {
+		return Event{}, false, nil
+	}
+
+	typ := Modified
+	if size, ok := w.filesizes[name]; ok && i.Size() < size {
+		typ = Truncated
+	}
+
+	if commit {
+		w.modtimes[name] = i.ModTime()
+		w.recordSize(name, i.Size())
+	}
+
+	if typ == Modified && w.isDuplicate(name, i.ModTime(), commit) {
+		return Event{}, false, nil
+	}
+
+	return Event{
+		Type:    typ,
+		Path:    name,
+		ModTime: i.ModTime(),
+		Size:    i.Size(),
+	}, true, nil
+}
+
+// recordSize records size as the size of the file name if truncation
+// detection is enabled. It must be called with mu being held.
+func (w *Watcher) recordSize(name string, size int64) {
+	if w.filesizes != nil {
+		w.filesizes[name] = size
+	}
+}
+
+// track records the state of the file name described by i. If checksum
+// detection is enabled, the file's checksum is computed and recorded, too.
+// It must be called with mu being held.
+func (w *Watcher) track(name string, i fs.FileInfo) error {
+	w.modtimes[name] = i.ModTime()
+	w.recordSize(name, i.Size())
+
+	if w.newHash != nil {
+		_, err := w.updateChecksum(name, true)
+		return err
+	}
+
+	return nil
+}
+
+// untrack removes all state recorded for the file name. It must be called
+// with mu being held.
+func (w *Watcher) untrack(name string) {
+	delete(w.modtimes, name)
+	delete(w.filesizes, name)
+	delete(w.checksums, name)
+	delete(w.seenModified, name)
+}
+
+// isDuplicate reports whether a Modified event for the file name with the
+// modification time modTime has already been emitted if deduplication has
+// been enabled using WithDeduplication. Otherwise, modTime is recorded if
+// commit is set. Zero modification times are never considered duplicates.
+// It must be called with mu being held.
+func (w *Watcher) isDuplicate(name string, modTime time.Time, commit bool) bool {
+	if w.seenModified == nil || modTime.IsZero() {
+		return false
 	}
+
+	if seen, ok := w.seenModified[name]; ok && seen.Equal(modTime) {
+		w.log(slog.LevelDebug, "suppressing duplicate event", slog.String("path", name))
+		return true
+	}
+
+	if commit {
+		w.seenModified[name] = modTime
+	}
+	return false
+}
+
+// log logs msg with attrs using the logger configured with WithLogger. It is
+// a no-op if no logger has been configured.
+func (w *Watcher) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if w.logger == nil {
+		return
+	}
+
+	w.logger.LogAttrs(context.Background(), level, msg, attrs...)
 }
@@ -1,6 +1,15 @@
 package globwatch
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,23 +36,23 @@ func TestWatcher_detecChanges(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := watcher.determineInitialState(); err != nil {
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
 	fsys.Touch("go.mod")
 	fsys.Touch("cmd/main_test.go")
 
-	watcher.detectChanges()
+	watcher.detectChanges(context.Background())
 
 	fsys.Touch("cmd/main_test.go")
 	fsys.Touch("internal/tool_test.go")
 
-	watcher.detectChanges()
+	watcher.detectChanges(context.Background())
 
 	fsys.Rm("internal")
 
-	watcher.detectChanges()
+	watcher.detectChanges(context.Background())
 
 	close(watcher.c)
 
@@ -52,7 +61,7 @@ func TestWatcher_detecChanges(t *testing.T) {
 		evts = append(evts, evt)
 	}
 
-	ExpectThat(t, evts).Is(DeepEqual([]Event{
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
 		{
 			Type: Created,
 			Path: "cmd/main_test.go",
@@ -77,6 +86,7 @@ func TestEventType_String(t *testing.T) {
 		Created:       "created",
 		Deleted:       "deleted",
 		Modified:      "modified",
+		Truncated:     "truncated",
 		EventType(99): "unknown",
 	}
 
@@ -84,3 +94,711 @@ func TestEventType_String(t *testing.T) {
 		ExpectThat(t, in.String()).Is(Equal(want))
 	}
 }
+
+func TestWatcher_Subscribe(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c1, unsubscribe1 := watcher.Subscribe()
+	c2, unsubscribe2 := watcher.Subscribe()
+
+	fsys.Touch("cmd/main.go")
+	watcher.detectChanges(context.Background())
+
+	unsubscribe1()
+
+	fsys.Touch("cmd/main_test.go")
+	watcher.detectChanges(context.Background())
+
+	unsubscribe2()
+	close(watcher.c)
+
+	collect := func(c <-chan Event) []Event {
+		evts := make([]Event, 0, 20)
+		for evt := range c {
+			evts = append(evts, evt)
+		}
+		return evts
+	}
+
+	modified := Event{Type: Modified, Path: "cmd/main.go"}
+	created := Event{Type: Created, Path: "cmd/main_test.go"}
+
+	ExpectThat(t, withoutInfo(collect(watcher.c))).Is(DeepEqual([]Event{modified, created}))
+	ExpectThat(t, withoutInfo(collect(c1))).Is(DeepEqual([]Event{modified}))
+	ExpectThat(t, withoutInfo(collect(c2))).Is(DeepEqual([]Event{modified, created}))
+}
+
+func TestWatcher_Subscribe_full(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < cap(c); i++ {
+		watcher.emit(Event{Type: Created, Path: "main.go"})
+		<-watcher.c
+	}
+
+	watcher.emit(Event{Type: Modified, Path: "main.go"})
+
+	ExpectThat(t, <-watcher.c).Is(Equal(Event{Type: Modified, Path: "main.go", WatcherID: watcher.ID(), SeqNum: uint64(cap(c) + 1)}))
+	ExpectThat(t, <-watcher.errors).Is(Error(ErrEventDropped))
+	ExpectThat(t, len(c)).Is(Equal(cap(c)))
+	ExpectThat(t, watcher.DroppedEventCount()).Is(Equal(uint64(1)))
+}
+
+func TestWatcher_WithFullChannelPolicy(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	flood := func(w *Watcher) {
+		for _, p := range []string{"a.go", "b.go", "c.go", "d.go"} {
+			w.emit(Event{Type: Created, Path: p})
+		}
+	}
+
+	received := func(w *Watcher) []string {
+		close(w.c)
+		var paths []string
+		for evt := range w.c {
+			paths = append(paths, evt.Path)
+		}
+		return paths
+	}
+
+	t.Run("DropOldest", func(t *testing.T) {
+		watcher, err := New(fsys, "**/*.go", time.Second, WithEventBufferSize(2), WithFullChannelPolicy(DropOldest))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flood(watcher)
+
+		ExpectThat(t, watcher.DroppedEventCount()).Is(Equal(uint64(2)))
+		ExpectThat(t, len(watcher.errors)).Is(Equal(0))
+		ExpectThat(t, received(watcher)).Is(DeepEqual([]string{"c.go", "d.go"}))
+	})
+
+	t.Run("DropNewest", func(t *testing.T) {
+		watcher, err := New(fsys, "**/*.go", time.Second, WithEventBufferSize(2), WithFullChannelPolicy(DropNewest))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flood(watcher)
+
+		ExpectThat(t, watcher.DroppedEventCount()).Is(Equal(uint64(2)))
+		ExpectThat(t, len(watcher.errors)).Is(Equal(0))
+		ExpectThat(t, received(watcher)).Is(DeepEqual([]string{"a.go", "b.go"}))
+	})
+
+	t.Run("BlockUntilDrained", func(t *testing.T) {
+		watcher, err := New(fsys, "**/*.go", time.Second, WithEventBufferSize(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			flood(watcher)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("expected emit to block")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		var paths []string
+		for len(paths) < 4 {
+			paths = append(paths, (<-watcher.c).Path)
+		}
+		<-done
+
+		ExpectThat(t, watcher.DroppedEventCount()).Is(Equal(uint64(0)))
+		ExpectThat(t, paths).Is(DeepEqual([]string{"a.go", "b.go", "c.go", "d.go"}))
+	})
+}
+
+func TestWatcher_OnEvent(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second, WithCallbacksOnly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var evts []Event
+	var errs []error
+
+	watcher.OnEvent(func(evt Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		evts = append(evts, evt)
+	})
+	watcher.OnEvent(func(evt Event) {
+		panic("kaboom")
+	})
+	watcher.OnError(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+
+	watcher.startCallbackWorkers()
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("cmd/main.go")
+	fsys.Touch("cmd/main_test.go")
+	watcher.detectChanges(context.Background())
+
+	watcher.stopCallbackWorkers()
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "cmd/main_test.go"},
+		{Type: Modified, Path: "cmd/main.go"},
+	}))
+	ExpectThat(t, len(errs)).Is(Equal(2))
+	for _, err := range errs {
+		ExpectThat(t, err).Is(Error(ErrHandlerPanic))
+	}
+
+	// Events have been delivered to the handlers only.
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+}
+
+func TestWatcher_OnEvent_restartAfterFailedStart(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	dir := filepath.Join(t.TempDir(), "logs")
+
+	watcher, err := New(fsys, "*.go", time.Millisecond, WithEventLog(filepath.Join(dir, "events.log")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evts := make(chan Event, 10)
+	watcher.OnEvent(func(evt Event) {
+		evts <- evt
+	})
+
+	ExpectThat(t, watcher.Start()).Is(Error(fs.ErrNotExist))
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	fsys.Touch("main.go")
+
+	evt := <-evts
+	ExpectThat(t, evt.Type).Is(Equal(Modified))
+	ExpectThat(t, evt.Path).Is(Equal("main.go"))
+}
+
+func TestWatcher_Close_notStarted(t *testing.T) {
+	watcher, err := New(fsmock.New(fsmock.NewDir("")), "*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher.Close()
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(false))
+	ExpectThat(t, watcher.Start()).Is(Error(ErrClosed))
+}
+
+func TestWatcher_OnCreate(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var created, modified, deleted []string
+
+	watcher.
+		OnCreate(func(evt Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			created = append(created, evt.Path)
+		}).
+		OnCreate(func(evt Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			created = append(created, "second:"+evt.Path)
+		}).
+		OnModify(func(evt Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			modified = append(modified, evt.Path)
+		}).
+		OnDelete(func(evt Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, evt.Path)
+		})
+
+	watcher.startCallbackWorkers()
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("cmd/main.go")
+	fsys.Touch("cmd/main_test.go")
+	watcher.detectChanges(context.Background())
+
+	fsys.Rm("cmd/main.go")
+	watcher.detectChanges(context.Background())
+
+	watcher.stopCallbackWorkers()
+
+	ExpectThat(t, created).Is(DeepEqual([]string{"cmd/main_test.go", "second:cmd/main_test.go"}))
+	ExpectThat(t, modified).Is(DeepEqual([]string{"cmd/main.go"}))
+	ExpectThat(t, deleted).Is(DeepEqual([]string{"cmd/main.go"}))
+
+	// Events have been delivered to C, too.
+	close(watcher.c)
+
+	evts := make([]Event, 0, 20)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "cmd/main_test.go"},
+		{Type: Modified, Path: "cmd/main.go"},
+		{Type: Deleted, Path: "cmd/main.go"},
+	}))
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Watch("missing.txt")).Is(Error(fs.ErrNotExist))
+
+	if err := watcher.Watch("go.mod"); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"cmd/main.go", "go.mod"}))
+
+	watcher.detectChanges(context.Background())
+
+	fsys.Touch("go.mod")
+	fsys.Touch("cmd/main.go")
+	watcher.detectChanges(context.Background())
+
+	fsys.Rm("go.mod")
+	watcher.detectChanges(context.Background())
+
+	fsys.Touch("go.mod")
+	watcher.detectChanges(context.Background())
+
+	watcher.Unwatch("go.mod")
+	fsys.Touch("go.mod")
+	watcher.detectChanges(context.Background())
+
+	close(watcher.c)
+
+	evts := make([]Event, 0, 20)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "cmd/main.go"},
+		{Type: Modified, Path: "go.mod"},
+		{Type: Deleted, Path: "go.mod"},
+		{Type: Created, Path: "go.mod"},
+	}))
+}
+
+func TestWatcher_WithTruncationDetection(t *testing.T) {
+	log := fsmock.TextFile("app.log", "first line\n")
+	fsys := fsmock.New(fsmock.NewDir("", log))
+
+	watcher, err := New(fsys, "*.log", time.Second, WithTruncationDetection(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	log.Content = append(log.Content, "second line\n"...)
+	log.ModTime = log.ModTime.Add(time.Second)
+	watcher.detectChanges(context.Background())
+
+	log.Content = []byte("new\n")
+	log.ModTime = log.ModTime.Add(time.Second)
+	watcher.detectChanges(context.Background())
+
+	close(watcher.c)
+
+	evts := make([]Event, 0, 20)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "app.log"},
+		{Type: Truncated, Path: "app.log"},
+	}))
+}
+
+func TestWatcher_WithChecksumDetection(t *testing.T) {
+	cfg := fsmock.TextFile("app.conf", "debug = false")
+	cfg.ModTime = time.Time{}
+	main := fsmock.TextFile("main.go", "package main")
+	fsys := fsmock.New(fsmock.NewDir("", cfg, main))
+
+	watcher, err := New(fsys, "*", time.Second, WithChecksumDetection(sha256.New))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// ModTime is not available; content changes.
+	cfg.Content = []byte("debug = true")
+	watcher.detectChanges(context.Background())
+
+	// ModTime changes; content stays the same.
+	fsys.Touch("main.go")
+	watcher.detectChanges(context.Background())
+
+	// ModTime and content change.
+	main.Content = []byte("package main\n\nfunc main() {}")
+	fsys.Touch("main.go")
+	watcher.detectChanges(context.Background())
+
+	close(watcher.c)
+
+	evts := make([]Event, 0, 20)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "app.conf"},
+		{Type: Modified, Path: "main.go"},
+	}))
+}
+
+func TestWatcher_WithLogger(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	watcher, err := New(fsys, "**/*.go", time.Second, WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < cap(c); i++ {
+		watcher.emit(Event{Type: Modified, Path: "cmd/main.go"})
+		<-watcher.c
+	}
+
+	fsys.Touch("cmd/main.go")
+	watcher.detectChanges(context.Background())
+	<-watcher.c
+	<-watcher.errors
+
+	watcher.fsys = failingFS{}
+	watcher.detectChanges(context.Background())
+	<-watcher.errors
+
+	log := buf.String()
+
+	ExpectThat(t, log).Is(StringContaining("level=DEBUG msg=\"poll completed\" poll_duration="))
+	ExpectThat(t, log).Is(StringContaining("files_scanned=1 events_emitted=1"))
+	ExpectThat(t, log).Is(StringContaining("level=WARN msg=\"event dropped due to full channel\" type=created path=cmd/main.go"))
+	ExpectThat(t, log).Is(StringContaining("level=ERROR msg=\"failed to walk directory\" error="))
+}
+
+func TestWatcher_detectChanges_sorted(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("z.go"),
+		fsmock.EmptyFile("y.go"),
+		fsmock.EmptyFile("x.go"),
+		fsmock.EmptyFile("c.go"),
+		fsmock.EmptyFile("b.go"),
+	))
+
+	watcher, err := New(fsys, "*.go", time.Second, WithEventBufferSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Rm("z.go")
+	fsys.Rm("x.go")
+	fsys.Touch("y.go")
+	fsys.Touch("c.go")
+	fsys.Touch("e.go")
+	fsys.Touch("a.go")
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+	close(watcher.c)
+	evts := make([]Event, 0, 6)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "a.go"},
+		{Type: Created, Path: "e.go"},
+		{Type: Modified, Path: "c.go"},
+		{Type: Modified, Path: "y.go"},
+		{Type: Deleted, Path: "x.go"},
+		{Type: Deleted, Path: "z.go"},
+	}))
+}
+
+func TestWatcher_HealthCheck(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.HealthCheck()).Is(DeepEqual(HealthStatus{}))
+
+	watcher.detectChanges(context.Background())
+	<-watcher.c
+
+	status := watcher.HealthCheck()
+	ExpectThat(t, status.LastPollAt.IsZero()).Is(Equal(false))
+	ExpectThat(t, status.FilesTracked).Is(Equal(1))
+	ExpectThat(t, status.ErrorsLast60s).Is(Equal(0))
+	// Not healthy as the watcher has not been started.
+	ExpectThat(t, status.Healthy).Is(Equal(false))
+
+	watcher.fsys = failingFS{}
+	watcher.detectChanges(context.Background())
+	<-watcher.errors
+
+	failed := watcher.HealthCheck()
+	ExpectThat(t, failed.ErrorsLast60s).Is(Equal(1))
+	ExpectThat(t, failed.LastPollAt).Is(Equal(status.LastPollAt))
+}
+
+// errIO is returned from failingFS. Unlike fs.ErrPermission it is treated
+// as a fatal error.
+var errIO = errors.New("i/o error")
+
+// failingFS implements an fs.FS that fails to open any file.
+type failingFS struct{}
+
+func (failingFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: errIO}
+}
+
+// withoutInfo returns a copy of evts with ModTime and Size cleared to ease
+// comparison.
+func withoutInfo(evts []Event) []Event {
+	res := make([]Event, len(evts))
+	for i, evt := range evts {
+		res[i] = Event{Type: evt.Type, Path: evt.Path}
+	}
+	return res
+}
+
+func TestWatcher_detectChanges_info(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("cmd/main.go")
+	watcher.detectChanges(context.Background())
+
+	fsys.Rm("cmd/main.go")
+	watcher.detectChanges(context.Background())
+
+	modified := <-watcher.c
+	ExpectThat(t, modified.Type).Is(Equal(Modified))
+	ExpectThat(t, modified.Size).Is(Equal(int64(len("package main"))))
+	ExpectThat(t, modified.ModTime.IsZero()).Is(Equal(false))
+
+	deleted := <-watcher.c
+	ExpectThat(t, deleted).Is(Equal(Event{Type: Deleted, Path: "cmd/main.go", WatcherID: watcher.ID(), SeqNum: 2}))
+}
+
+func TestNew_bufferSize(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := New(fsys, "**/*.go", time.Second)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, cap(watcher.C())).Is(Equal(10))
+	ExpectThat(t, cap(watcher.ErrorsChan())).Is(Equal(10))
+
+	watcher, err = New(fsys, "**/*.go", time.Second, WithEventBufferSize(100), WithErrorBufferSize(0))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, cap(watcher.C())).Is(Equal(100))
+	ExpectThat(t, cap(watcher.ErrorsChan())).Is(Equal(0))
+
+	_, err = New(fsys, "**/*.go", time.Second, WithEventBufferSize(-1))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+
+	_, err = New(fsys, "**/*.go", time.Second, WithErrorBufferSize(-1))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+}
+
+// recordingTelemetry records all metrics passed to it.
+type recordingTelemetry struct {
+	polls, events, errors, files int
+}
+
+func (r *recordingTelemetry) RecordPollDuration(time.Duration) { r.polls++ }
+func (r *recordingTelemetry) RecordEventsEmitted(n int)        { r.events += n }
+func (r *recordingTelemetry) RecordErrors(n int)               { r.errors += n }
+func (r *recordingTelemetry) RecordFilesScanned(n int)         { r.files += n }
+
+func TestWatcher_WithTelemetry(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.EmptyFile("util.go"),
+		fsmock.EmptyFile("go.mod"),
+	))
+
+	tel := &recordingTelemetry{}
+	watcher, err := New(fsys, "*.go", time.Second, WithTelemetry(tel))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("main.go")
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+	ExpectThat(t, *tel).Is(DeepEqual(recordingTelemetry{polls: 1, events: 1, errors: 0, files: 2}))
+}
+
+func TestWatcher_WithDeduplication(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	watcher, err := New(fsys, "*.go", time.Second, WithDeduplication(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("main.go")
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, (<-watcher.c).Type).Is(Equal(Modified))
+
+	// Unchanged file
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+
+	// Simulate an incorrectly recorded modification time.
+	watcher.mu.Lock()
+	watcher.modtimes["main.go"] = time.Time{}.Add(time.Second)
+	watcher.mu.Unlock()
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+
+	fsys.Touch("main.go")
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, (<-watcher.c).Type).Is(Equal(Modified))
+}
@@ -1,10 +1,14 @@
 module github.com/halimath/globwatch
 
-go 1.18
+go 1.23
 
 require (
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
 	github.com/halimath/fsmock v0.0.0-20221112192818-cff727715cba
 )
 
-require github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+require (
+	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
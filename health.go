@@ -0,0 +1,90 @@
+package globwatch
+
+import (
+	"time"
+)
+
+const (
+	// healthErrorWindow is the window errors are counted in by HealthCheck.
+	healthErrorWindow = time.Minute
+
+	// healthStaleFactor is the number of poll intervals after which a
+	// Watcher without a successful poll is considered unhealthy.
+	healthStaleFactor = 3
+)
+
+// HealthStatus describes the health of a Watcher as reported by
+// HealthCheck.
+type HealthStatus struct {
+	// Healthy is true if the Watcher is running and has completed a poll
+	// recently.
+	Healthy bool `json:"healthy"`
+	// LastPollAt is the time the last successful poll has been completed.
+	LastPollAt time.Time `json:"lastPollAt"`
+	// LastPollDuration is the duration of the last successful poll. It is
+	// encoded as nanoseconds.
+	LastPollDuration time.Duration `json:"lastPollDuration"`
+	// ErrorsLast60s is the number of errors reported within the last
+	// minute.
+	ErrorsLast60s int `json:"errorsLast60s"`
+	// FilesTracked is the number of files currently tracked.
+	FilesTracked int `json:"filesTracked"`
+}
+
+// HealthCheck returns w's current health status. w is considered healthy if
+// it is running and the last successful poll completed no longer than three
+// poll intervals (see CurrentInterval) ago. Errors do not render w unhealthy
+// by themselves unless they prevent polls from completing.
+func (w *Watcher) HealthCheck() HealthStatus {
+	now := time.Now()
+
+	w.healthMu.Lock()
+	w.pruneErrorTimes(now)
+	status := HealthStatus{
+		LastPollAt:       w.lastPollAt,
+		LastPollDuration: w.lastPollDuration,
+		ErrorsLast60s:    len(w.errorTimes),
+	}
+	w.healthMu.Unlock()
+
+	status.FilesTracked = w.Count()
+
+	maxAge := healthStaleFactor*w.CurrentInterval() + status.LastPollDuration
+	status.Healthy = w.IsRunning() &&
+		!status.LastPollAt.IsZero() &&
+		now.Sub(status.LastPollAt) <= maxAge
+
+	return status
+}
+
+// recordPoll records the completion of a successful poll started at start.
+func (w *Watcher) recordPoll(start time.Time) {
+	now := time.Now()
+
+	w.healthMu.Lock()
+	defer w.healthMu.Unlock()
+
+	w.lastPollAt = now
+	w.lastPollDuration = now.Sub(start)
+}
+
+// recordError records an error being reported for HealthCheck.
+func (w *Watcher) recordError() {
+	now := time.Now()
+
+	w.healthMu.Lock()
+	defer w.healthMu.Unlock()
+
+	w.pruneErrorTimes(now)
+	w.errorTimes = append(w.errorTimes, now)
+}
+
+// pruneErrorTimes discards the times of errors reported before the error
+// window. It must be called with healthMu being held.
+func (w *Watcher) pruneErrorTimes(now time.Time) {
+	i := 0
+	for i < len(w.errorTimes) && now.Sub(w.errorTimes[i]) > healthErrorWindow {
+		i++
+	}
+	w.errorTimes = w.errorTimes[i:]
+}
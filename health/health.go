@@ -0,0 +1,32 @@
+// Package health provides an http.Handler reporting the health of a
+// globwatch.Watcher. Mount the handler at /healthz to integrate a watcher
+// with health checks of container orchestrators or load balancers:
+//
+//	http.Handle("/healthz", health.HealthHandler(watcher))
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/halimath/globwatch"
+)
+
+// HealthHandler returns an http.Handler that responds with watcher's
+// globwatch.HealthStatus encoded as JSON. The response's status code is 200
+// if watcher is healthy and 503 otherwise.
+func HealthHandler(watcher *globwatch.Watcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := watcher.HealthCheck()
+
+		code := http.StatusOK
+		if !status.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(status)
+	})
+}
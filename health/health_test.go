@@ -0,0 +1,78 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestHealthHandler(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := HealthHandler(watcher)
+
+	get := func() (int, map[string]any) {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		ExpectThat(t, rec.Header().Get("Content-Type")).Is(Equal("application/json"))
+
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		return rec.Code, body
+	}
+
+	code, body := get()
+	ExpectThat(t, code).Is(Equal(http.StatusServiceUnavailable))
+	ExpectThat(t, body["healthy"]).Is(Equal(any(false)))
+
+	go func() {
+		for range watcher.C() {
+		}
+	}()
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	<-watcher.NextPoll()
+
+	code, body = get()
+	ExpectThat(t, code).Is(Equal(http.StatusOK))
+	ExpectThat(t, body["healthy"]).Is(Equal(any(true)))
+	ExpectThat(t, body["filesTracked"]).Is(Equal(any(float64(1))))
+	ExpectThat(t, body["errorsLast60s"]).Is(Equal(any(float64(0))))
+
+	lastPollAt, err := time.Parse(time.RFC3339Nano, body["lastPollAt"].(string))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, time.Since(lastPollAt) < time.Second).Is(Equal(true))
+
+	_, ok := body["lastPollDuration"].(float64)
+	ExpectThat(t, ok).Is(Equal(true))
+
+	watcher.Close()
+
+	code, body = get()
+	ExpectThat(t, code).Is(Equal(http.StatusServiceUnavailable))
+	ExpectThat(t, body["healthy"]).Is(Equal(any(false)))
+}
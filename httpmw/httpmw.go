@@ -0,0 +1,74 @@
+// Package httpmw provides a net/http middleware that synchronizes request
+// handling with a globwatch.Watcher's poll cycle.
+//
+// This is useful for development servers that rebuild assets when source
+// files change: By waiting for the watcher to complete a poll cycle before
+// handling a request, all changes made before the request arrived are
+// guaranteed to have been detected (and reported) when the request is
+// handled.
+package httpmw
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/halimath/globwatch"
+)
+
+// Option customizes the middleware.
+type Option func(*middleware)
+
+// WithMaxWait bounds the time a request waits for the watcher's poll cycle to
+// d. Once d elapsed, the request is forwarded even if the poll cycle has not
+// completed. A value of zero (the default) disables the limit.
+func WithMaxWait(d time.Duration) Option {
+	return func(m *middleware) {
+		m.maxWait = d
+	}
+}
+
+type middleware struct {
+	watcher *globwatch.Watcher
+	next    http.Handler
+	maxWait time.Duration
+}
+
+// Middleware returns an http.Handler that blocks each request until watcher
+// completed at least one successful poll cycle started after the request
+// arrived and then forwards the request to next. If watcher is not running,
+// requests are forwarded immediately. If the client cancels the request
+// while waiting, the request is not forwarded.
+func Middleware(watcher *globwatch.Watcher, next http.Handler, opts ...Option) http.Handler {
+	m := &middleware{
+		watcher: watcher,
+		next:    next,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.watcher.IsRunning() {
+		polled := m.watcher.NextPoll()
+
+		var timeout <-chan time.Time
+		if m.maxWait > 0 {
+			timer := time.NewTimer(m.maxWait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-polled:
+		case <-timeout:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	m.next.ServeHTTP(w, r)
+}
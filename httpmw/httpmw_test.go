@@ -0,0 +1,74 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestMiddleware(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*.go", 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var forwarded time.Time
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = time.Now()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("not running", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		Middleware(watcher, next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		ExpectThat(t, rec.Code).Is(Equal(http.StatusNoContent))
+	})
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	t.Run("waits for poll", func(t *testing.T) {
+		polled := watcher.NextPoll()
+		start := time.Now()
+
+		rec := httptest.NewRecorder()
+		Middleware(watcher, next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		ExpectThat(t, rec.Code).Is(Equal(http.StatusNoContent))
+
+		select {
+		case <-polled:
+		default:
+			t.Error("request forwarded before poll completed")
+		}
+
+		ExpectThat(t, forwarded.After(start)).Is(Equal(true))
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		polled := watcher.NextPoll()
+
+		rec := httptest.NewRecorder()
+		Middleware(watcher, next, WithMaxWait(time.Nanosecond)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		ExpectThat(t, rec.Code).Is(Equal(http.StatusNoContent))
+
+		select {
+		case <-polled:
+			t.Error("expected request to be forwarded before poll completed")
+		default:
+		}
+	})
+}
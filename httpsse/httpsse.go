@@ -0,0 +1,62 @@
+// Package httpsse provides an http.Handler streaming the events reported by a
+// globwatch.Watcher to HTTP clients using Server-Sent Events.
+//
+// Each event is sent as a single SSE frame with the event marshaled to JSON
+// as its data:
+//
+//	data: {"type":"modified","path":"cmd/main.go",...}
+package httpsse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/halimath/globwatch"
+)
+
+// Handler returns an http.Handler that streams all events reported by
+// watcher as Server-Sent Events. Each request subscribes to watcher
+// independently so any number of clients may be connected concurrently. The
+// subscription is canceled when the client disconnects. The response ends
+// when watcher is closed.
+func Handler(watcher *globwatch.Watcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		events, unsubscribe := watcher.Subscribe()
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(evt)
+				if err != nil {
+					continue
+				}
+
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package httpsse
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestHandler(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(Handler(watcher))
+	defer srv.Close()
+
+	// Connect two clients before starting the watcher so both are subscribed
+	// when the first change is detected.
+	clients := make([]*bufio.Reader, 2)
+	for i := range clients {
+		res, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusOK))
+		ExpectThat(t, res.Header.Get("Content-Type")).Is(Equal("text/event-stream"))
+
+		clients[i] = bufio.NewReader(res.Body)
+	}
+
+	go func() {
+		for range watcher.C() {
+		}
+	}()
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	fsys.Touch("cmd/main_test.go")
+
+	for _, c := range clients {
+		evt := readEvent(t, c)
+		ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+		ExpectThat(t, evt.Path).Is(Equal("cmd/main_test.go"))
+	}
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) globwatch.Event {
+	t.Helper()
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(line, "data: ") {
+		t.Fatalf("unexpected line: %q", line)
+	}
+
+	var evt globwatch.Event
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &evt); err != nil {
+		t.Fatal(err)
+	}
+
+	if blank, err := r.ReadString('\n'); err != nil || blank != "\n" {
+		t.Fatalf("expected blank line but got %q (%v)", blank, err)
+	}
+
+	return evt
+}
@@ -0,0 +1,25 @@
+package globwatch
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// ID returns the watcher's ID as set with WithID or generated when the
+// watcher has been created.
+func (w *Watcher) ID() string {
+	return w.id
+}
+
+// newWatcherID generates a random (version 4) UUID.
+func newWatcherID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate watcher id: %w", err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
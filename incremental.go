@@ -0,0 +1,122 @@
+package globwatch
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/halimath/globwatch/pattern"
+)
+
+// shadowDir records the state of a single directory as seen during the last
+// incremental scan.
+type shadowDir struct {
+	// The directory's modification time
+	modTime time.Time
+	// Paths of all files directly contained in the directory that match the
+	// pattern
+	files []string
+	// Paths of all subdirectories
+	dirs []string
+}
+
+// scanIncremental scans the filesystem and returns entries for all files
+// matching w's pattern.
+func (w *Watcher) scanIncremental(ctx context.Context, werrs *walkErrors) ([]pattern.Entry, error) {
+	entries := make([]pattern.Entry, 0)
+	shadow := make(map[string]*shadowDir, len(w.shadow))
+
+	if err := w.scanDir(ctx, ".", &entries, shadow, werrs); err != nil {
+		return entries, err
+	}
+
+	w.shadow = shadow
+
+	return entries, nil
+}
+
+// scanDir scans dir and all of its subdirectories recursively appending
+// entries for all matching files to entries. dir is only read if its
+// modification time differs from the one recorded in w.shadow. The state of
+// all scanned directories is recorded in shadow. Non-fatal errors are
+// collected in werrs.
+func (w *Watcher) scanDir(ctx context.Context, dir string, entries *[]pattern.Entry, shadow map[string]*shadowDir, werrs *walkErrors) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	info, err := fs.Stat(w.fsys, dir)
+	if err != nil {
+		return w.handleWalkError(dir, err, werrs)
+	}
+
+	sd, ok := w.shadow[dir]
+	if ok && info.ModTime().Equal(sd.modTime) {
+		// The directory is unchanged so its list of files is still valid.
+		// Each file is stat'ed to get its current modification time.
+		found, ok := w.statFiles(sd.files)
+		if ok {
+			*entries = append(*entries, found...)
+		} else {
+			// A file vanished without the directory's modtime being updated;
+			// fall back to reading the directory.
+			sd = nil
+		}
+	} else {
+		sd = nil
+	}
+
+	if sd == nil {
+		dirEntries, err := fs.ReadDir(w.fsys, dir)
+		if err != nil {
+			return w.handleWalkError(dir, err, werrs)
+		}
+
+		sd = &shadowDir{
+			modTime: info.ModTime(),
+		}
+
+		for _, e := range dirEntries {
+			p := path.Join(dir, e.Name())
+
+			if e.IsDir() {
+				if w.withinWalkDepth(p) {
+					sd.dirs = append(sd.dirs, p)
+				}
+				continue
+			}
+
+			if w.currentPattern().Match(p) {
+				sd.files = append(sd.files, p)
+				*entries = append(*entries, pattern.Entry{Path: p, DirEntry: e})
+			}
+		}
+	}
+
+	shadow[dir] = sd
+
+	for _, d := range sd.dirs {
+		if err := w.scanDir(ctx, d, entries, shadow, werrs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// statFiles stats all files given by paths and returns entries for them. It
+// returns false if any file cannot be stat'ed.
+func (w *Watcher) statFiles(paths []string) ([]pattern.Entry, bool) {
+	entries := make([]pattern.Entry, 0, len(paths))
+
+	for _, p := range paths {
+		i, err := fs.Stat(w.fsys, p)
+		if err != nil {
+			return nil, false
+		}
+		entries = append(entries, pattern.Entry{Path: p, DirEntry: fs.FileInfoToDirEntry(i)})
+	}
+
+	return entries, true
+}
@@ -0,0 +1,108 @@
+package globwatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_incrementalScan(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+			fsmock.NewDir("cli",
+				fsmock.EmptyFile("cli.go"),
+			),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second, WithIncrementalScan(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{
+		"cmd/main.go",
+		"internal/cli/cli.go",
+		"internal/tool.go",
+	}))
+
+	// Modifying a file does not change the directory's modtime.
+	fsys.Touch("internal/cli/cli.go")
+	watcher.detectChanges(context.Background())
+
+	// Creating a file does. fsmock does not update the directory's modtime so
+	// this is done manually.
+	fsys.Touch("cmd/main_test.go")
+	fsys.Touch("cmd")
+	watcher.detectChanges(context.Background())
+
+	fsys.Rm("internal/cli")
+	fsys.Touch("internal")
+	watcher.detectChanges(context.Background())
+
+	close(watcher.c)
+
+	evts := make([]Event, 0, 20)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "internal/cli/cli.go"},
+		{Type: Created, Path: "cmd/main_test.go"},
+		{Type: Deleted, Path: "internal/cli/cli.go"},
+	}))
+}
+
+func benchmarkDetectChanges(b *testing.B, opts ...Option) {
+	dirs := make([]fsmock.Entry, 0, 100)
+	for i := 0; i < 100; i++ {
+		files := make([]fsmock.Entry, 0, 100)
+		for j := 0; j < 100; j++ {
+			files = append(files, fsmock.EmptyFile(fmt.Sprintf("file_%03d.go", j)))
+		}
+		dirs = append(dirs, fsmock.NewDir(fmt.Sprintf("dir_%03d", i), files...))
+	}
+	fsys := fsmock.New(fsmock.NewDir("", dirs...))
+
+	watcher, err := New(fsys, "**/*.go", time.Second, opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fsys.Touch(fmt.Sprintf("dir_042/new_%d.go", i))
+		fsys.Touch("dir_042")
+
+		watcher.detectChanges(context.Background())
+		<-watcher.c
+	}
+}
+
+func BenchmarkWatcher_detectChanges_full(b *testing.B) {
+	benchmarkDetectChanges(b)
+}
+
+func BenchmarkWatcher_detectChanges_incremental(b *testing.B) {
+	benchmarkDetectChanges(b, WithIncrementalScan(true))
+}
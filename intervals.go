@@ -0,0 +1,130 @@
+package globwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"time"
+
+	"github.com/halimath/globwatch/pattern"
+)
+
+// intervalGroup defines a group of tracked files checked for changes using
+// their own interval.
+type intervalGroup struct {
+	pat      *pattern.Pattern
+	interval time.Duration
+}
+
+// newIntervalGroups creates interval groups from intervals. Groups are
+// sorted by pattern to make their order deterministic.
+func newIntervalGroups(intervals map[string]time.Duration) ([]intervalGroup, error) {
+	pats := make([]string, 0, len(intervals))
+	for pat := range intervals {
+		pats = append(pats, pat)
+	}
+	slices.Sort(pats)
+
+	groups := make([]intervalGroup, 0, len(pats))
+	for _, pat := range pats {
+		p, err := pattern.New(pat)
+		if err != nil {
+			return nil, fmt.Errorf("%w: per pattern interval: %w", ErrInvalidOption, err)
+		}
+
+		if intervals[pat] <= 0 {
+			return nil, fmt.Errorf("%w: non-positive interval for pattern %q: %s", ErrInvalidOption, pat, intervals[pat])
+		}
+
+		groups = append(groups, intervalGroup{pat: p, interval: intervals[pat]})
+	}
+
+	return groups, nil
+}
+
+// startIntervalGroups starts a goroutine for each interval group which checks
+// the group's files every group interval.
+func (w *Watcher) startIntervalGroups(ctx context.Context) {
+	w.groupsDone = make(chan struct{})
+
+	for _, g := range w.groups {
+		w.groupsWG.Add(1)
+		go func() {
+			defer w.groupsWG.Done()
+
+			ticker := time.NewTicker(g.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					w.scanGroup(g)
+				case <-w.groupsDone:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// stopIntervalGroups stops all goroutines started by startIntervalGroups and
+// waits for them to finish.
+func (w *Watcher) stopIntervalGroups() {
+	close(w.groupsDone)
+	w.groupsWG.Wait()
+}
+
+// scanGroup checks all tracked files belonging to g for modifications and
+// deletions. New files are only detected by a full change detection.
+func (w *Watcher) scanGroup(g intervalGroup) {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	var events []Event
+	var errs []error
+
+	w.mu.Lock()
+	for name := range w.modtimes {
+		if !g.pat.Match(name) {
+			continue
+		}
+
+		i, err := fs.Stat(w.fsys, name)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+				continue
+			}
+
+			w.untrack(name)
+			events = append(events, Event{
+				Type: Deleted,
+				Path: name,
+			})
+			continue
+		}
+
+		evt, changed, err := w.compare(name, i, true)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if changed {
+			events = append(events, evt)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, err := range errs {
+		w.reportError(err)
+	}
+
+	sortEvents(events)
+
+	w.publish(events)
+}
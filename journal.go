@@ -0,0 +1,70 @@
+package globwatch
+
+import (
+	"fmt"
+	"sync"
+)
+
+// journal implements a ring buffer holding the most recent events emitted by
+// a Watcher. See WithJournal.
+type journal struct {
+	mu   sync.Mutex
+	buf  []Event
+	head int
+	n    int
+}
+
+func newJournal(capacity int) *journal {
+	return &journal{
+		buf: make([]Event, capacity),
+	}
+}
+
+// add appends evt to j overwriting the oldest event if j is full.
+func (j *journal) add(evt Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.n == len(j.buf) {
+		j.buf[j.head] = evt
+		j.head = (j.head + 1) % len(j.buf)
+		return
+	}
+
+	j.buf[(j.head+j.n)%len(j.buf)] = evt
+	j.n++
+}
+
+// last returns up to n of the most recent events in the order they have
+// been emitted.
+func (j *journal) last(n int) []Event {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	n = min(n, j.n)
+
+	events := make([]Event, n)
+	for i := range events {
+		events[i] = j.buf[(j.head+j.n-n+i)%len(j.buf)]
+	}
+
+	return events
+}
+
+// ReplayFrom returns up to n of the most recent events emitted by w in the
+// order they have been emitted. Events are recorded in a journal enabled with
+// WithJournal; ReplayFrom returns ErrNoJournal if no journal has been
+// enabled. A consumer starting late may call ReplayFrom before receiving
+// from C to catch up on past events. As events are emitted concurrently, the
+// replayed events and the events received from C may overlap.
+func (w *Watcher) ReplayFrom(n int) ([]Event, error) {
+	if w.journal == nil {
+		return nil, ErrNoJournal
+	}
+
+	if n < 0 {
+		return nil, fmt.Errorf("invalid number of events to replay: %d", n)
+	}
+
+	return w.journal.last(n), nil
+}
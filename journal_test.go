@@ -0,0 +1,89 @@
+package globwatch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_WithJournal(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("a.go"),
+		fsmock.EmptyFile("b.go"),
+		fsmock.EmptyFile("c.go"),
+		fsmock.EmptyFile("d.go"),
+		fsmock.EmptyFile("e.go"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond,
+		globwatch.WithJournal(3),
+		globwatch.WithInitialEvents(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := watcher.ReplayFrom(10)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, events).Is(DeepEqual([]globwatch.Event{}))
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	for i := 0; i < 5; i++ {
+		<-watcher.C()
+	}
+
+	events, err = watcher.ReplayFrom(10)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, paths(events)).Is(DeepEqual([]string{"c.go", "d.go", "e.go"}))
+
+	// A consumer starting late catches up using the journal and continues
+	// with C.
+	fsys.Touch("f.go")
+	evt := <-watcher.C()
+
+	events, err = watcher.ReplayFrom(2)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, paths(events)).Is(DeepEqual([]string{"e.go", "f.go"}))
+	ExpectThat(t, events[1].Type).Is(Equal(globwatch.Created))
+	ExpectThat(t, events[1].Path).Is(Equal(evt.Path))
+}
+
+func TestWatcher_ReplayFrom_invalid(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = watcher.ReplayFrom(1)
+	ExpectThat(t, err).Is(Error(globwatch.ErrNoJournal))
+
+	watcher, err = globwatch.New(fsys, "*.go", time.Millisecond, globwatch.WithJournal(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = watcher.ReplayFrom(-1)
+	ExpectThat(t, err).Is(NotNil())
+
+	_, err = globwatch.New(fsys, "*.go", time.Millisecond, globwatch.WithJournal(-1))
+	ExpectThat(t, err).Is(Error(globwatch.ErrInvalidOption))
+}
+
+// paths returns the paths of events.
+func paths(events []globwatch.Event) []string {
+	p := make([]string, len(events))
+	for i, evt := range events {
+		p[i] = evt.Path
+	}
+	return p
+}
@@ -0,0 +1,93 @@
+package globwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MarshalJSON marshals t as a JSON string using the representation returned
+// from String.
+func (t EventType) MarshalJSON() ([]byte, error) {
+	switch t {
+	case Created, Modified, Deleted, Truncated, Heartbeat:
+		return json.Marshal(t.String())
+	default:
+		return nil, fmt.Errorf("invalid event type: %d", int(t))
+	}
+}
+
+// UnmarshalJSON unmarshals t from a JSON string as produced by MarshalJSON.
+func (t *EventType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	switch s {
+	case "created":
+		*t = Created
+	case "modified":
+		*t = Modified
+	case "deleted":
+		*t = Deleted
+	case "truncated":
+		*t = Truncated
+	case "heartbeat":
+		*t = Heartbeat
+	default:
+		return fmt.Errorf("invalid event type: %q", s)
+	}
+
+	return nil
+}
+
+// jsonEvent defines the JSON representation of an Event.
+type jsonEvent struct {
+	Type    EventType  `json:"type"`
+	Path    string     `json:"path"`
+	ModTime *time.Time `json:"modTime,omitempty"`
+	Size    int64      `json:"size,omitempty"`
+	Watcher string     `json:"watcherId,omitempty"`
+	Seq     uint64     `json:"seq,omitempty"`
+}
+
+// MarshalJSON marshals e as a JSON object. The event's type is encoded as a
+// string. ModTime, Size, WatcherID and SeqNum are omitted if zero.
+func (e Event) MarshalJSON() ([]byte, error) {
+	je := jsonEvent{
+		Type:    e.Type,
+		Path:    e.Path,
+		Size:    e.Size,
+		Watcher: e.WatcherID,
+		Seq:     e.SeqNum,
+	}
+
+	if !e.ModTime.IsZero() {
+		je.ModTime = &e.ModTime
+	}
+
+	return json.Marshal(je)
+}
+
+// UnmarshalJSON unmarshals e from a JSON object as produced by MarshalJSON.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var je jsonEvent
+	if err := json.Unmarshal(data, &je); err != nil {
+		return err
+	}
+
+	*e = Event{
+		Type:      je.Type,
+		Path:      je.Path,
+		Size:      je.Size,
+		WatcherID: je.Watcher,
+		SeqNum:    je.Seq,
+	}
+
+	if je.ModTime != nil {
+		e.ModTime = *je.ModTime
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package globwatch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestEvent_String(t *testing.T) {
+	ExpectThat(t, Event{Type: Created, Path: "cmd/main.go"}.String()).Is(Equal("created: cmd/main.go"))
+	ExpectThat(t, Event{Type: Deleted, Path: "main.go", WatcherID: "src", SeqNum: 3}.String()).Is(Equal("deleted: main.go [src#3]"))
+}
+
+func TestEventType_JSON(t *testing.T) {
+	for _, typ := range []EventType{Created, Modified, Deleted, Truncated, Heartbeat} {
+		data, err := json.Marshal(typ)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(data)).Is(Equal(`"` + typ.String() + `"`))
+
+		var got EventType
+		ExpectThat(t, json.Unmarshal(data, &got)).Is(NoError())
+		ExpectThat(t, got).Is(Equal(typ))
+	}
+
+	_, err := json.Marshal(EventType(99))
+	ExpectThat(t, err).Is(NotNil())
+
+	var got EventType
+	ExpectThat(t, json.Unmarshal([]byte(`"unknown"`), &got)).Is(NotNil())
+	ExpectThat(t, json.Unmarshal([]byte(`1`), &got)).Is(NotNil())
+}
+
+func TestEvent_JSON(t *testing.T) {
+	modTime := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]Event{
+		`{"type":"created","path":"cmd/main.go","modTime":"2022-11-01T12:00:00Z","size":12}`: {
+			Type:    Created,
+			Path:    "cmd/main.go",
+			ModTime: modTime,
+			Size:    12,
+		},
+		`{"type":"deleted","path":"cmd/main.go"}`: {
+			Type: Deleted,
+			Path: "cmd/main.go",
+		},
+		`{"type":"modified","path":"main.go","watcherId":"src","seq":7}`: {
+			Type:      Modified,
+			Path:      "main.go",
+			WatcherID: "src",
+			SeqNum:    7,
+		},
+	}
+
+	for want, evt := range tests {
+		data, err := json.Marshal(evt)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(data)).Is(Equal(want))
+
+		var got Event
+		ExpectThat(t, json.Unmarshal(data, &got)).Is(NoError())
+		ExpectThat(t, got).Is(DeepEqual(evt))
+	}
+}
@@ -0,0 +1,90 @@
+package globwatch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WriteManifest writes the paths and modification times of all files
+// currently tracked by w to out in the format read by WithManifest: one file
+// per line consisting of the path and the RFC3339 formatted modification
+// time separated by a tab. Files are written in lexical order.
+func (w *Watcher) WriteManifest(out io.Writer) error {
+	w.mu.RLock()
+	lines := make([]string, 0, len(w.modtimes))
+	for name, modtime := range w.modtimes {
+		lines = append(lines, name+"\t"+modtime.Format(time.RFC3339Nano)+"\n")
+	}
+	w.mu.RUnlock()
+
+	slices.Sort(lines)
+
+	bw := bufio.NewWriter(out)
+	for _, l := range lines {
+		if _, err := bw.WriteString(l); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// loadManifest seeds the tracked files from the manifest set with
+// WithManifest. If the manifest cannot be read, the error is reported and w
+// falls back to walking the filesystem.
+func (w *Watcher) loadManifest() {
+	if w.manifest == nil || w.restored {
+		return
+	}
+
+	modtimes, err := w.readManifest(w.manifest)
+	if err != nil {
+		w.reportError(fmt.Errorf("failed to read manifest: %w", err))
+		return
+	}
+
+	w.mu.Lock()
+	w.modtimes = modtimes
+	w.mu.Unlock()
+
+	w.restored = true
+}
+
+// readManifest reads the modification times of all files listed in r which
+// match w's pattern.
+func (w *Watcher) readManifest(r io.Reader) (map[string]time.Time, error) {
+	pat := w.currentPattern()
+	modtimes := make(map[string]time.Time)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		name, ts, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing modification time", n)
+		}
+
+		modtime, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		if pat.Match(name) {
+			modtimes[name] = modtime
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return modtimes, nil
+}
@@ -0,0 +1,84 @@
+package globwatch_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_WithManifest(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.EmptyFile("tool.go"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Close()
+
+	var manifest bytes.Buffer
+	ExpectThat(t, watcher.WriteManifest(&manifest)).Is(NoError())
+	ExpectThat(t, strings.Count(manifest.String(), "\n")).Is(Equal(2))
+	ExpectThat(t, strings.HasPrefix(manifest.String(), "main.go\t")).Is(Equal(true))
+
+	fsys.Touch("new.go")
+
+	seeded, err := globwatch.New(fsys, "*.go", time.Millisecond, globwatch.WithManifest(&manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := seeded.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer seeded.Close()
+
+	select {
+	case evt := <-seeded.C():
+		ExpectThat(t, globwatch.Event{Type: evt.Type, Path: evt.Path}).Is(DeepEqual(globwatch.Event{
+			Type: globwatch.Created,
+			Path: "new.go",
+		}))
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+
+	select {
+	case evt := <-seeded.C():
+		t.Fatalf("unexpected event: %v", evt)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWatcher_WithManifest_invalid(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond,
+		globwatch.WithManifest(strings.NewReader("main.go\tyesterday\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	ExpectThat(t, (<-watcher.ErrorsChan()).Error()).Is(StringContaining("line 1"))
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"main.go"}))
+}
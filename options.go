@@ -0,0 +1,410 @@
+package globwatch
+
+import (
+	"hash"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Option defines a function that customizes a Watcher. Options are passed to
+// New.
+type Option func(*Watcher)
+
+// WithCallbackWorkers sets the number of goroutines used to invoke handlers
+// registered via OnEvent and OnError. Defaults to 1. With more than one
+// worker, handlers may be invoked concurrently and out of order. Values less
+// than 1 are treated as 1.
+func WithCallbackWorkers(n int) Option {
+	return func(w *Watcher) {
+		if n < 1 {
+			n = 1
+		}
+		w.callbackWorkers = n
+	}
+}
+
+// WithCallbacksOnly configures the watcher to deliver events and errors only
+// to the handlers registered via OnEvent and OnError. If enabled, events are
+// not sent to C and errors are not sent to ErrorsChan as long as at least
+// one handler of the respective kind is registered.
+func WithCallbacksOnly(enabled bool) Option {
+	return func(w *Watcher) {
+		w.callbacksOnly = enabled
+	}
+}
+
+// WithEventRateLimit limits the number of events emitted to n per duration
+// per using a token bucket. The bucket allows bursts of up to n events. Events
+// exceeding the limit are dropped and counted (see DroppedEvents). The limit
+// applies to each event after change detection and before it is delivered to
+// any channel or handler. As dropped events are not retried, a file whose
+// change has been dropped will be reported again only after it changed once
+// more.
+//
+// With a coalesce window set using WithCoalesceWindow, the limit applies to
+// the coalesced events once the window has elapsed, so merged events consume
+// a single token. Modified events suppressed by WithDeduplication never
+// consume a token; a Modified event dropped due to the limit is nevertheless
+// recorded as seen and is not reported again for the same modification time.
+// New returns ErrInvalidOption if n or per is not positive.
+func WithEventRateLimit(n int, per time.Duration) Option {
+	return func(w *Watcher) {
+		w.limiter = newTokenBucket(n, per)
+	}
+}
+
+// WithDropHandler registers fn to be invoked for every event dropped due to
+// the rate limit set with WithEventRateLimit. fn is invoked synchronously from
+// the change detection goroutine and should return quickly.
+func WithDropHandler(fn func(Event)) Option {
+	return func(w *Watcher) {
+		w.dropHandler = fn
+	}
+}
+
+// WithLogger sets a logger used to report diagnostic messages. Each poll
+// cycle is logged at debug level, events dropped due to a full subscriber
+// channel as well as directories skipped due to non-fatal errors are logged
+// at warn level and fatal directory walk errors are logged at error level. By
+// default, no messages are logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(w *Watcher) {
+		w.logger = l
+	}
+}
+
+// WithIncrementalScan enables incremental directory scanning. When enabled,
+// the watcher remembers each directory's modification time and contents and
+// only re-reads a directory if its modification time changed. Files found in
+// an unchanged directory are still checked for modifications.
+//
+// This relies on the filesystem updating a directory's modification time
+// whenever an entry is added to or removed from it, which is true for most
+// local filesystems but not guaranteed for every fs.FS implementation. Thus,
+// incremental scanning is disabled by default.
+func WithIncrementalScan(enabled bool) Option {
+	return func(w *Watcher) {
+		w.incremental = enabled
+	}
+}
+
+// WithTruncationDetection enables reporting Truncated events. When enabled,
+// the watcher records each file's size in addition to its modification time.
+// A changed file whose size is less than the previously recorded size is
+// reported as Truncated instead of Modified. This is useful to distinguish
+// log rotation from appending to a file.
+func WithTruncationDetection(enabled bool) Option {
+	return func(w *Watcher) {
+		w.truncation = enabled
+	}
+}
+
+// WithChecksumDetection enables content based change detection for
+// filesystems that do not update a file's modification time reliably. When
+// enabled, the watcher records a checksum of each file's content computed
+// with a hash created by newHash. A file is reported as Modified only if its
+// checksum changed.
+//
+// To avoid excessive I/O, a file's content is only hashed if its modification
+// time changed or is not available (i.e. is zero). Note that hashing requires
+// reading every such file completely on each poll.
+func WithChecksumDetection(newHash func() hash.Hash) Option {
+	return func(w *Watcher) {
+		w.newHash = newHash
+	}
+}
+
+// WithInitialEvents configures the watcher to emit a Created event for every
+// file found during the initial scan performed by StartContext. The events
+// are queued for delivery when StartContext returns and are emitted before
+// any event detected by subsequent polls. By default, files found during the
+// initial scan are tracked silently.
+func WithInitialEvents(enabled bool) Option {
+	return func(w *Watcher) {
+		w.initialEvents = enabled
+	}
+}
+
+// WithEventBufferSize sets the capacity of the channel returned from C to n.
+// Defaults to 10. A value of zero creates an unbuffered channel so each event
+// must be received before change detection continues. As sending to C blocks
+// change detection, set n to at least the number of files expected to change
+// within a single interval. New returns ErrInvalidOption if n is negative.
+func WithEventBufferSize(n int) Option {
+	return func(w *Watcher) {
+		w.eventBufferSize = n
+	}
+}
+
+// WithErrorBufferSize sets the capacity of the channel returned from
+// ErrorsChan to n. Defaults to 10. A value of zero creates an unbuffered
+// channel. New returns ErrInvalidOption if n is negative.
+func WithErrorBufferSize(n int) Option {
+	return func(w *Watcher) {
+		w.errorBufferSize = n
+	}
+}
+
+// WithFullChannelPolicy sets the policy applied when sending an event to a
+// full C. Defaults to BlockUntilDrained which blocks change detection until
+// the event has been received. With DropOldest or DropNewest, change
+// detection never blocks on C; dropped events are counted (see
+// DroppedEventCount) but not reported as errors.
+func WithFullChannelPolicy(p ChannelPolicy) Option {
+	return func(w *Watcher) {
+		w.channelPolicy = p
+	}
+}
+
+// WithEventQueueSize enables a queue holding up to n events between change
+// detection and C. Events are moved from the queue to C by a dedicated
+// goroutine so a slow consumer only fills up the queue instead of stalling
+// change detection. Once the queue is full, the policy set with
+// WithFullChannelPolicy applies: BlockUntilDrained blocks change detection,
+// DropOldest overwrites the oldest queued event and DropNewest discards the
+// new event. Events still queued when the watcher is closed can be obtained
+// using Drain.
+// A value of zero (the default) disables the queue. New returns
+// ErrInvalidOption if n is negative.
+func WithEventQueueSize(n int) Option {
+	return func(w *Watcher) {
+		w.eventQueueSize = n
+	}
+}
+
+// WithCoalesceWindow enables coalescing events for the same path. Events are
+// collected for d after the first event has been detected and are emitted
+// afterwards. Multiple events for the same path are merged into a single
+// event describing the change from the state before the first to the state
+// after the last event: a file deleted and created again is reported as
+// Modified, a file created and deleted again is not reported at all. Created
+// and Deleted take precedence over Truncated which in turn takes precedence
+// over Modified. d should be smaller than the interval. Pending events
+// are emitted immediately when the watcher is closed. By default, events are
+// emitted as soon as they have been detected.
+func WithCoalesceWindow(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.coalesceWindow = d
+	}
+}
+
+// WithFatalErrorPredicate sets fn to decide whether an error encountered
+// while walking the watched directories aborts the walk. If fn returns false,
+// the error is reported, the directory that caused the error is skipped and
+// the walk continues. Files tracked in a skipped directory are not reported
+// as Deleted. By default, errors wrapping fs.ErrPermission or fs.ErrNotExist
+// are non-fatal; all other errors are fatal.
+func WithFatalErrorPredicate(fn func(error) bool) Option {
+	return func(w *Watcher) {
+		w.fatalError = fn
+	}
+}
+
+// WithMaxConsecutiveErrors stops the watcher after n consecutive polls failed
+// with a fatal error. ErrMaxErrorsExceeded wrapping the last error is
+// reported before all channels are closed. A successful poll resets the count.
+// A value of zero (the default) keeps the watcher running regardless of
+// errors.
+func WithMaxConsecutiveErrors(n int) Option {
+	return func(w *Watcher) {
+		w.maxFailures = n
+	}
+}
+
+// WithErrorBackoff enables backing off polling after failed polls. After the
+// first failed poll, the watcher waits for base before polling again. The
+// wait time doubles with each consecutive failed poll up to max. The first
+// successful poll resets the wait time to the regular interval. Use
+// CurrentInterval to inspect the current wait time. base must not be
+// negative and max must be positive and not less than base; otherwise New
+// returns ErrInvalidOption.
+func WithErrorBackoff(base, max time.Duration) Option {
+	return func(w *Watcher) {
+		w.backoffBase = base
+		w.backoffMax = max
+	}
+}
+
+// WithFollowSymlinks enables following symbolic links. When enabled, a
+// symlinked file is tracked using the symlink's path while its modification
+// time is taken from the link's target. Symlinked directories are walked as
+// if they were regular directories; symlink cycles are not followed but
+// reported as ErrSymlinkCycle (see WithMaxSymlinkDepth). Note that following
+// symlinks requires an fs.FS that resolves symlinks when calling fs.Stat and
+// fs.ReadDir such as the one returned from os.DirFS. Following symlinks
+// disables incremental scanning (see WithIncrementalScan).
+func WithFollowSymlinks(enabled bool) Option {
+	return func(w *Watcher) {
+		w.followSymlinks = enabled
+	}
+}
+
+// WithMaxSymlinkDepth sets the maximum number of symlinks followed to reach a
+// single file or directory when following symlinks (see
+// WithFollowSymlinks). Symlinks exceeding the depth are skipped and reported
+// as ErrSymlinkCycle. On platforms where files cannot be identified by device
+// and inode number, this is the only protection against symlink cycles. The
+// default is 40; n must not be negative.
+func WithMaxSymlinkDepth(n int) Option {
+	return func(w *Watcher) {
+		w.maxSymlinkDepth = n
+	}
+}
+
+// WithTelemetry sets t to receive metrics about each change detection such
+// as its duration and the number of events emitted. By default, metrics are
+// discarded.
+func WithTelemetry(t Telemetry) Option {
+	return func(w *Watcher) {
+		w.telemetry = t
+	}
+}
+
+// WithPerPatternInterval sets individual intervals for files matching the
+// patterns given as keys of intervals. Each group of files is checked for
+// modifications and deletions every group interval independently of the
+// interval passed to New. Files not matching any of the patterns as well as
+// new files are detected using the interval passed to New. The patterns are
+// relative to the watched filesystem's root just like the watcher's pattern;
+// a file matching multiple patterns is checked by all matching groups.
+// Invalid patterns or non-positive intervals cause New to return an error
+// wrapping ErrInvalidOption.
+func WithPerPatternInterval(intervals map[string]time.Duration) Option {
+	return func(w *Watcher) {
+		w.intervals = intervals
+	}
+}
+
+// WithCheckpointFile enables persisting the state of tracked files to the
+// file path. When started, the watcher restores its state from path if the
+// file exists (see LoadCheckpoint). While running, the state is saved to path
+// every saveInterval and once more when the watcher stops (see
+// SaveCheckpoint). Errors saving the file are reported via the errors
+// channel.
+func WithCheckpointFile(path string, saveInterval time.Duration) Option {
+	return func(w *Watcher) {
+		w.checkpointFile = path
+		w.checkpointInterval = saveInterval
+	}
+}
+
+// WithManifest seeds the files tracked by the watcher from the manifest read
+// from r instead of walking the filesystem when the watcher is started. The
+// manifest lists one file per line consisting of the path relative to the
+// watched root and its RFC3339 formatted modification time separated by a
+// tab, i.e.
+//
+//	cmd/main.go	2022-11-12T19:28:18Z
+//
+// Use WriteManifest to generate a manifest. Like a restored checkpoint (see
+// LoadCheckpoint), the watcher does not report Created events for the
+// listed files; the first change detection reports all changes since the
+// manifest has been generated. If r is nil, the filesystem is walked as
+// usual. If reading the manifest fails, the error is reported via the errors
+// channel and the watcher falls back to walking the filesystem. A checkpoint
+// takes precedence over a manifest.
+func WithManifest(r io.Reader) Option {
+	return func(w *Watcher) {
+		w.manifest = r
+	}
+}
+
+// WithJournal enables recording the last capacity events emitted by the
+// watcher in a journal. Use ReplayFrom to retrieve recorded events, e.g. to
+// catch up a consumer that starts receiving events after the watcher has
+// been started. A capacity of zero (the default) disables the journal.
+func WithJournal(capacity int) Option {
+	return func(w *Watcher) {
+		w.journalCapacity = capacity
+	}
+}
+
+// WithHeartbeat enables sending a Heartbeat event to C whenever a poll cycle
+// completes without detecting any change. This allows consumers to tell an
+// idle watcher from a stalled one by receiving from C with a timeout. The
+// event's path is empty.
+func WithHeartbeat(enabled bool) Option {
+	return func(w *Watcher) {
+		w.heartbeat = enabled
+	}
+}
+
+// WithID sets the watcher's ID which is set as WatcherID on every event the
+// watcher emits. By default, a random UUID is generated.
+func WithID(id string) Option {
+	return func(w *Watcher) {
+		w.id = id
+	}
+}
+
+// WithWalkDepth limits the directories walked to find files matching the
+// pattern to maxDepth levels below the watched root. Files contained in the
+// root are at depth zero, files contained in a direct subdirectory of the
+// root at depth one and so on. Files deeper than maxDepth are never
+// reported, regardless of the pattern. Unlike pattern.WithMaxDepth, which
+// limits the directories matched by a single **, the limit applies to the
+// path as a whole. A value of zero (the default) means unlimited.
+func WithWalkDepth(maxDepth int) Option {
+	return func(w *Watcher) {
+		w.walkDepth = maxDepth
+	}
+}
+
+// WithAutoDiscoverDepth exempts newly created directories from the walk depth
+// set with WithWalkDepth. A directory beyond the walk depth that did not
+// exist during the previous scan is walked as long as it does not exceed the
+// walk depth by more than d levels. This ensures that files created along
+// with a new directory are reported. Once discovered, the directory is
+// walked during subsequent scans as well so that changes to its files are
+// reported until the directory is removed. Directories existing when the
+// watcher is started are never discovered. A value of zero (the default)
+// disables auto discovery. The option has no effect without a walk depth and
+// when scanning incrementally (see WithIncrementalScan).
+func WithAutoDiscoverDepth(d int) Option {
+	return func(w *Watcher) {
+		w.autoDiscoverDepth = d
+	}
+}
+
+// WithGracefulClose enables closing the watcher gracefully. By default, Close
+// cancels a change detection in progress and discards its results. With
+// graceful close enabled, Close lets a change detection in progress finish
+// and performs a final one before closing C, so that consumers receive all
+// changes made up to the moment of shutdown. Note that Close blocks until all
+// events have been sent to C; depending on the policy set with
+// WithFullChannelPolicy, this requires C to be consumed.
+func WithGracefulClose(enabled bool) Option {
+	return func(w *Watcher) {
+		w.gracefulClose = enabled
+	}
+}
+
+// WithDeduplication enables suppressing repeated Modified events. When
+// enabled, the watcher records the modification time reported with each
+// Modified event and never reports another Modified event for the same file
+// and modification time. This guards consumers against repeated events in
+// case a file's modification time is recorded incorrectly, i.e. when a
+// deployment preserves timestamps. Files without a modification time are
+// not deduplicated.
+func WithDeduplication(enabled bool) Option {
+	return func(w *Watcher) {
+		w.deduplication = enabled
+	}
+}
+
+// WithEventLog enables persisting all events emitted by the watcher to the
+// file path. The file is opened in append mode (and created if it does not
+// exist) when the watcher is started; starting fails if the file cannot be
+// opened. Each event is written as a single line of JSON in the format
+// described for NewLoggingWatcher. Writes are buffered and flushed after
+// each change detection as well as when the watcher is closed. Use
+// ReplayEventLog to read the events back.
+//
+// The log file is never rotated or truncated by the watcher. Use an external
+// tool such as logrotate to limit its size.
+func WithEventLog(path string) Option {
+	return func(w *Watcher) {
+		w.eventLogFile = path
+	}
+}
@@ -0,0 +1,41 @@
+package globwatch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NewFromOS creates a new watcher watching the operating system's directory
+// dir. Unlike using New with os.DirFS, events as well as the path names
+// returned from Files and Snapshot carry absolute paths using the operating
+// system's path separator. pat is still relative to dir. Paths passed to
+// Watch and Unwatch are relative to dir, too.
+//
+// NewFromOS requires access to the real filesystem and cannot be used with
+// mock filesystems; use New for those.
+func NewFromOS(dir, pat string, interval time.Duration, opts ...Option) (*Watcher, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := New(os.DirFS(absDir), pat, interval, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w.absDir = absDir
+
+	return w, nil
+}
+
+// externalPath returns the path reported to users for the file name. It is
+// name unless w has been created using NewFromOS.
+func (w *Watcher) externalPath(name string) string {
+	if w.absDir == "" {
+		return name
+	}
+
+	return w.absDir + string(filepath.Separator) + filepath.FromSlash(name)
+}
@@ -1,11 +1,17 @@
 package globwatch_test
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/halimath/fsmock"
 	"github.com/halimath/globwatch"
+	"github.com/halimath/globwatch/pattern"
 
 	. "github.com/halimath/expect-go"
 )
@@ -36,7 +42,7 @@ func TestWatcher(t *testing.T) {
 
 	go func() {
 		for evt := range watcher.C() {
-			evts = append(evts, evt)
+			evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
 		}
 	}()
 
@@ -56,3 +62,652 @@ func TestWatcher(t *testing.T) {
 
 	watcher.Close()
 }
+
+func TestWatcher_Files(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool_test.go"),
+		),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*_test.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{
+					"cmd/main_test.go",
+					"internal/tool_test.go",
+				}))
+				ExpectThat(t, watcher.Count()).Is(Equal(2))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestWatcher_IsRunning(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(false))
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(true))
+	ExpectThat(t, watcher.Start()).Is(Error(globwatch.ErrAlreadyStarted))
+
+	time.Sleep(5 * time.Millisecond)
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(true))
+
+	watcher.Close()
+
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(false))
+}
+
+func TestWatcher_Reset(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*.go", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Reset()).Is(Error(globwatch.ErrClosed))
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Reset()).Is(NoError())
+
+	for _, want := range []string{"cmd/main.go", "cmd/main_test.go"} {
+		evt := <-watcher.C()
+		ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+		ExpectThat(t, evt.Path).Is(Equal(want))
+	}
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"cmd/main.go", "cmd/main_test.go"}))
+
+	watcher.Close()
+
+	ExpectThat(t, watcher.Reset()).Is(Error(globwatch.ErrClosed))
+}
+
+func TestWatcher_DetectChanges(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*.go", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+
+	evt := <-watcher.C()
+	ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+	ExpectThat(t, evt.Path).Is(Equal("cmd/main.go"))
+
+	fsys.Touch("cmd/main.go")
+	fsys.Touch("cmd/main_test.go")
+
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+
+	for _, want := range []globwatch.Event{
+		{Type: globwatch.Created, Path: "cmd/main_test.go"},
+		{Type: globwatch.Modified, Path: "cmd/main.go"},
+	} {
+		evt := <-watcher.C()
+		ExpectThat(t, globwatch.Event{Type: evt.Type, Path: evt.Path}).Is(DeepEqual(want))
+	}
+
+	ExpectThat(t, len(watcher.C())).Is(Equal(0))
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Rm("cmd/main_test.go")
+
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+
+	evt = <-watcher.C()
+	ExpectThat(t, evt.Type).Is(Equal(globwatch.Deleted))
+	ExpectThat(t, evt.Path).Is(Equal("cmd/main_test.go"))
+
+	watcher.Close()
+
+	ExpectThat(t, watcher.DetectChanges()).Is(Error(globwatch.ErrClosed))
+}
+
+func TestWatcher_DetectChanges_handlers(t *testing.T) {
+	files := make([]fsmock.Entry, 0, 20)
+	for i := range 20 {
+		files = append(files, fsmock.EmptyFile(fmt.Sprintf("file%02d.go", i)))
+	}
+	fsys := fsmock.New(fsmock.NewDir("", files...))
+
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Hour,
+		globwatch.WithEventBufferSize(50),
+		globwatch.WithEventLog(path),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var handled []string
+	watcher.OnEvent(func(evt globwatch.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, evt.Path)
+	})
+
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+	ExpectThat(t, len(handled)).Is(Equal(20))
+
+	fsys.Touch("file00.go")
+
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+	ExpectThat(t, len(handled)).Is(Equal(21))
+	ExpectThat(t, handled[20]).Is(Equal("file00.go"))
+
+	logged, err := globwatch.ReplayEventLog(path)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(logged)).Is(Equal(21))
+	ExpectThat(t, logged[20].Type).Is(Equal(globwatch.Modified))
+}
+
+func TestWatcher_DetectChanges_coalesced(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Hour,
+		globwatch.WithCoalesceWindow(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var handled []globwatch.Event
+	watcher.OnEvent(func(evt globwatch.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, evt)
+	})
+
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+
+	fsys.Touch("main.go")
+	ExpectThat(t, watcher.DetectChanges()).Is(NoError())
+
+	// Let a coalesce window elapse after the last scan.
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ExpectThat(t, len(handled)).Is(Equal(2))
+	ExpectThat(t, handled[0].Type).Is(Equal(globwatch.Created))
+	ExpectThat(t, handled[1].Type).Is(Equal(globwatch.Modified))
+}
+
+func TestWatcher_WithInitialEvents(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool_test.go"),
+		),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*_test.go", time.Millisecond, globwatch.WithInitialEvents(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	evts := make([]globwatch.Event, 0, 3)
+	for len(evts) < 2 {
+		evt := <-watcher.C()
+		evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
+	}
+
+	fsys.Touch("cmd/main_test.go")
+
+	evt := <-watcher.C()
+	evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
+
+	ExpectThat(t, evts).Is(DeepEqual([]globwatch.Event{
+		{Type: globwatch.Created, Path: "internal/tool_test.go"},
+		{Type: globwatch.Created, Path: "cmd/main_test.go"},
+		{Type: globwatch.Modified, Path: "cmd/main_test.go"},
+	}))
+}
+
+func TestWatcher_Events(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*_test.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan []globwatch.Event)
+	go func() {
+		var evts []globwatch.Event
+		for evt := range watcher.Events() {
+			evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
+			if len(evts) == 1 {
+				watcher.Close()
+			}
+		}
+		done <- evts
+	}()
+
+	fsys.Touch("main_test.go")
+
+	select {
+	case evts := <-done:
+		ExpectThat(t, evts).Is(DeepEqual([]globwatch.Event{
+			{Type: globwatch.Created, Path: "main_test.go"},
+		}))
+	case <-time.After(time.Second):
+		t.Fatal("Events did not terminate after Close")
+	}
+}
+
+func TestWatcher_Events_canceled(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*_test.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := watcher.StartContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for range watcher.Events() {
+		}
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Events did not terminate after cancelation")
+	}
+}
+
+func TestNewFromOS(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "main_test.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := globwatch.NewFromOS(dir, "**/*_test.go", time.Millisecond, globwatch.WithInitialEvents(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	evt := <-watcher.C()
+	ExpectThat(t, globwatch.Event{Type: evt.Type, Path: evt.Path}).Is(DeepEqual(globwatch.Event{
+		Type: globwatch.Created,
+		Path: filepath.Join(dir, "main_test.go"),
+	}))
+
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{filepath.Join(dir, "main_test.go")}))
+
+	_, ok := watcher.Snapshot()[filepath.Join(dir, "main_test.go")]
+	ExpectThat(t, ok).Is(Equal(true))
+}
+
+func TestNewFromConfig(t *testing.T) {
+	for name, incremental := range map[string]bool{"incremental": true, "default": false} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			writeFile := func(name, content string) {
+				t.Helper()
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			writeFile(globwatch.ConfigFile, "# sources\n**/*.go\n\n!**/*_test.go\n")
+			writeFile("main.go", "package main")
+			writeFile("main_test.go", "package main")
+			writeFile("README.md", "")
+
+			interval := 20 * time.Millisecond
+			watcher, err := globwatch.NewFromConfig(dir, interval, globwatch.WithIncrementalScan(incremental))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := watcher.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer watcher.Close()
+
+			ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"main.go"}))
+
+			writeFile(globwatch.ConfigFile, "*.md\n")
+			// Make sure the modification is detected on filesystems with a coarse
+			// modification time resolution.
+			future := time.Now().Add(time.Second)
+			if err := os.Chtimes(filepath.Join(dir, globwatch.ConfigFile), future, future); err != nil {
+				t.Fatal(err)
+			}
+
+			evts := make([]globwatch.Event, 0, 2)
+			timeout := time.After(2*interval + time.Second)
+
+			for len(evts) < 2 {
+				select {
+				case evt := <-watcher.C():
+					evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
+				case <-timeout:
+					t.Fatalf("timeout waiting for events; got %v", evts)
+				}
+			}
+
+			ExpectThat(t, evts).Is(DeepEqual([]globwatch.Event{
+				{Type: globwatch.Created, Path: "README.md"},
+				{Type: globwatch.Deleted, Path: "main.go"},
+			}))
+		})
+	}
+}
+
+func TestNewFromConfig_invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, globwatch.ConfigFile), []byte("*.go\n[a-\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := globwatch.NewFromConfig(dir, time.Second)
+	ExpectThat(t, err).Is(Error(pattern.ErrBadPattern))
+	ExpectThat(t, err.Error()).Is(StringContaining(globwatch.ConfigFile + ":2"))
+}
+
+func TestWatcher_WithPerPatternInterval(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("app.log"),
+		fsmock.EmptyFile("main.go"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*", time.Hour, globwatch.WithPerPatternInterval(map[string]time.Duration{
+		"*.log": 5 * time.Millisecond,
+		"*.go":  200 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	fsys.Touch("main.go")
+	fsys.Touch("app.log")
+
+	evts := make([]globwatch.Event, 0, 2)
+	for len(evts) < 2 {
+		evt := <-watcher.C()
+		evts = append(evts, globwatch.Event{Type: evt.Type, Path: evt.Path})
+	}
+
+	ExpectThat(t, evts).Is(DeepEqual([]globwatch.Event{
+		{Type: globwatch.Modified, Path: "app.log"},
+		{Type: globwatch.Modified, Path: "main.go"},
+	}))
+}
+
+func TestWithPerPatternInterval_invalid(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	_, err := globwatch.New(fsys, "*", time.Second, globwatch.WithPerPatternInterval(map[string]time.Duration{"[a-": time.Second}))
+	ExpectThat(t, err).Is(Error(globwatch.ErrInvalidOption))
+
+	_, err = globwatch.New(fsys, "*", time.Second, globwatch.WithPerPatternInterval(map[string]time.Duration{"*.go": 0}))
+	ExpectThat(t, err).Is(Error(globwatch.ErrInvalidOption))
+}
+
+func TestWatcher_WithHeartbeat(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	interval := 10 * time.Millisecond
+	watcher, err := globwatch.New(fsys, "*.go", interval, globwatch.WithHeartbeat(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case evt := <-watcher.C():
+			ExpectThat(t, evt).Is(DeepEqual(globwatch.Event{Type: globwatch.Heartbeat, WatcherID: watcher.ID()}))
+		case <-time.After(time.Second):
+			t.Fatal("no heartbeat received")
+		}
+	}
+
+	ExpectThat(t, time.Since(start) >= 3*interval).Is(Equal(true))
+
+	// Heartbeats are not sent for poll cycles detecting changes.
+	fsys.Touch("tool.go")
+	for evt := range watcher.C() {
+		if evt.Type != globwatch.Heartbeat {
+			ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+			ExpectThat(t, evt.Path).Is(Equal("tool.go"))
+			break
+		}
+	}
+}
+
+func TestWatcher_eventMetadata(t *testing.T) {
+	src := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+	lib := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("lib.go"),
+	))
+
+	srcWatcher, err := globwatch.New(src, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	libWatcher, err := globwatch.New(lib, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := globwatch.New(lib, "*.go", time.Millisecond, globwatch.WithID("lib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, srcWatcher.ID() != libWatcher.ID()).Is(Equal(true))
+	ExpectThat(t, len(srcWatcher.ID())).Is(Equal(36))
+	ExpectThat(t, named.ID()).Is(Equal("lib"))
+
+	if err := srcWatcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srcWatcher.Close()
+
+	if err := libWatcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer libWatcher.Close()
+
+	receive := func(w *globwatch.Watcher) globwatch.Event {
+		t.Helper()
+
+		select {
+		case evt := <-w.C():
+			return evt
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+			return globwatch.Event{}
+		}
+	}
+
+	var last uint64
+	for i := 0; i < 3; i++ {
+		src.Touch("main.go")
+		evt := receive(srcWatcher)
+		ExpectThat(t, evt.WatcherID).Is(Equal(srcWatcher.ID()))
+		ExpectThat(t, evt.SeqNum > last).Is(Equal(true))
+		last = evt.SeqNum
+	}
+
+	lib.Touch("lib.go")
+	evt := receive(libWatcher)
+	ExpectThat(t, evt.WatcherID).Is(Equal(libWatcher.ID()))
+	ExpectThat(t, evt.SeqNum).Is(Equal(uint64(1)))
+}
+
+func TestWatcher_WithGracefulClose(t *testing.T) {
+	for name, graceful := range map[string]bool{"graceful": true, "default": false} {
+		t.Run(name, func(t *testing.T) {
+			fsys := fsmock.New(fsmock.NewDir("",
+				fsmock.EmptyFile("main.go"),
+			))
+
+			watcher, err := globwatch.New(fsys, "*.go", time.Hour, globwatch.WithGracefulClose(graceful))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := watcher.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			fsys.Touch("tool.go")
+			watcher.Close()
+
+			var paths []string
+			for evt := range watcher.C() {
+				paths = append(paths, evt.Path)
+			}
+
+			if graceful {
+				ExpectThat(t, paths).Is(DeepEqual([]string{"tool.go"}))
+			} else {
+				ExpectThat(t, len(paths)).Is(Equal(0))
+			}
+		})
+	}
+}
+
+func TestNewWithContext(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, err := globwatch.NewWithContext(ctx, fsys, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(true))
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"main.go"}))
+
+	cancel()
+
+	select {
+	case _, ok := <-watcher.C():
+		ExpectThat(t, ok).Is(Equal(false))
+	case <-time.After(time.Second):
+		t.Fatal("C not closed after context has been canceled")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for watcher.IsRunning() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(false))
+
+	_, err = globwatch.NewWithContext(context.Background(), fsys, "[", time.Millisecond)
+	ExpectThat(t, err).Is(NotNil())
+}
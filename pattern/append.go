@@ -0,0 +1,75 @@
+package pattern
+
+import "fmt"
+
+// Append returns a new pattern matching paths consisting of a path matched by
+// pat followed by a path matched by suffix. The new pattern's tokens are the
+// concatenation of pat's and suffix's tokens; a separator is inserted if pat
+// does not end and suffix does not start with one, so
+//
+//	pattern.MustNew("src").Append(pattern.MustNew("**/*.go"))
+//
+// is equivalent to pattern.MustNew("src/**/*.go"). The new pattern uses the
+// options pat has been created with.
+//
+// Append returns an error wrapping ErrBadPattern if the concatenation is not
+// a valid pattern, i.e. if pat ends and suffix starts with a separator, if
+// either pattern has been created using Any, All or Not or if suffix carries
+// a drive letter.
+func (pat *Pattern) Append(suffix *Pattern) (*Pattern, error) {
+	if pat.op != opNone || suffix.op != opNone {
+		return nil, fmt.Errorf("%w: cannot append combined patterns", ErrBadPattern)
+	}
+
+	if suffix.drive != "" {
+		return nil, fmt.Errorf("%w: cannot append pattern with drive letter %s", ErrBadPattern, suffix.drive)
+	}
+
+	tokens := make([]token, 0, len(pat.tokens)+len(suffix.tokens)+1)
+	tokens = append(tokens, pat.tokens...)
+	source := pat.source
+
+	if len(pat.tokens) > 0 && len(suffix.tokens) > 0 {
+		endsWithSep := isSeparator(pat.tokens[len(pat.tokens)-1])
+		startsWithSep := isSeparator(suffix.tokens[0])
+
+		if endsWithSep && startsWithSep {
+			return nil, fmt.Errorf("%w: unexpected // appending %q to %q", ErrBadPattern, suffix.source, pat.source)
+		}
+
+		if !endsWithSep && !startsWithSep {
+			tokens = append(tokens, token{tokenTypeLiteral, Separator, runeGroup{}})
+			source += string(Separator)
+		}
+	}
+
+	tokens = append(tokens, suffix.tokens...)
+	source += suffix.source
+
+	p := &Pattern{
+		source:         source,
+		tokens:         tokens,
+		maxDepth:       pat.maxDepth,
+		maxComplexity:  pat.maxComplexity,
+		unicodeFolding: pat.unicodeFolding,
+		windowsPaths:   pat.windowsPaths,
+		drive:          pat.drive,
+	}
+
+	if p.unicodeFolding {
+		foldGroups(p.tokens)
+	}
+	p.prefix, p.prefixTokens = literalPrefix(tokens)
+	p.suffix = literalSuffix(tokens)
+
+	if err := p.checkComplexity(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// isSeparator reports whether t matches a literal separator.
+func isSeparator(t token) bool {
+	return t.t == tokenTypeLiteral && t.r == Separator
+}
@@ -0,0 +1,60 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_Append(t *testing.T) {
+	paths := []string{
+		"src/main.go",
+		"src/cmd/main.go",
+		"src/cmd/main_test.go",
+		"src/README.md",
+		"lib/main.go",
+		"src.go",
+	}
+
+	tests := map[[2]string]string{
+		{"src", "**/*.go"}:    "src/**/*.go",
+		{"src/", "**/*.go"}:   "src/**/*.go",
+		{"src", "/*.go"}:      "src/*.go",
+		{"**", "*_test.go"}:   "**/*_test.go",
+		{"src/**", "main.go"}: "src/**/main.go",
+		{"", "*.go"}:          "*.go",
+		{"src", ""}:           "src",
+	}
+
+	for in, want := range tests {
+		got, err := MustNew(in[0]).Append(MustNew(in[1]))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, got.GoString()).Is(Equal(MustNew(want).GoString()))
+		ExpectThat(t, got.MatchList(paths)).Is(DeepEqual(MustNew(want).MatchList(paths)))
+	}
+}
+
+func TestPattern_Append_options(t *testing.T) {
+	base := MustNewWithOptions("[s]rc", WithUnicodeFolding())
+
+	p, err := base.Append(MustNew("[m]ain.go"))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, p.Match("Src/Main.go")).Is(Equal(true))
+
+	_, err = MustNewWithOptions("**", WithMaxComplexity(4)).Append(MustNew("**/*.go"))
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+}
+
+func TestPattern_Append_invalid(t *testing.T) {
+	_, err := MustNew("src/").Append(MustNew("/main.go"))
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+
+	_, err = Any(MustNew("src"), MustNew("lib")).Append(MustNew("*.go"))
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+
+	defer func(w bool) { isWindows = w }(isWindows)
+	isWindows = true
+
+	_, err = MustNew("src").Append(MustNewWithOptions("C:/*.go", WithWindowsPaths()))
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+}
@@ -26,6 +26,8 @@ func BenchmarkGlobwatch_simple_reuse(b *testing.B) {
 }
 
 func BenchmarkGlobwatch_simple_noreuse(b *testing.B) {
+	b.ReportAllocs()
+
 	for i := 0; i < b.N; i++ {
 		p, err := New(simplePattern)
 		if err != nil {
@@ -92,3 +94,92 @@ func BenchmarkGlobwatch_directoryWildcard_noreuse(b *testing.B) {
 		p.Match("bar/foo_test.go")
 	}
 }
+
+var matchListPaths = []string{
+	"main.go",
+	"main_test.go",
+	"bar/foo_test.go",
+	"bar/foo.go",
+	"internal/bar/foo_test.go",
+	"internal/bar/foo.go",
+	"internal/bar/baz/foo_test.go",
+	"internal/bar/baz/foo.go",
+}
+
+func BenchmarkGlobwatch_matchList(b *testing.B) {
+	p, err := New(directoryWildcardPattern)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.MatchList(matchListPaths)
+	}
+}
+
+func BenchmarkGlobwatch_matchLoop(b *testing.B) {
+	p, err := New(directoryWildcardPattern)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		res := make([]bool, len(matchListPaths))
+		for j, f := range matchListPaths {
+			res[j] = p.Match(f)
+		}
+	}
+}
+
+// BenchmarkGlobwatch_matchAllocs tracks the allocations of a single Match
+// call using a directory wildcard which causes the most backtracking. Match
+// is expected to report 0 allocs/op: the matcher passes offsets into the
+// matched path rather than re-slicing it so no intermediate strings are
+// created.
+func BenchmarkGlobwatch_matchAllocs(b *testing.B) {
+	p, err := New(directoryWildcardPattern)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.Match("internal/bar/baz/foo_test.go")
+	}
+}
+
+const longPrefixPattern = "internal/server/handlers/**/*_test.go"
+
+func BenchmarkGlobwatch_longPrefix_match(b *testing.B) {
+	p, err := New(longPrefixPattern)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.Match("internal/server/handlers/api/v1/user_test.go")
+	}
+}
+
+func BenchmarkGlobwatch_longPrefix_reject(b *testing.B) {
+	p, err := New(longPrefixPattern)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.Match("internal/server/middleware/api/v1/user_test.go")
+	}
+}
@@ -0,0 +1,101 @@
+package pattern
+
+import (
+	"context"
+	"io/fs"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CachedPattern wraps a Pattern and caches the result of the last call to
+// GlobFS or GlobFSContext. As long as the cached result is younger than the
+// configured TTL, globbing the same fs.FS and root returns the cached result
+// without walking the filesystem. Use it for repeated globbing of a
+// filesystem that changes infrequently, i.e. inside an HTTP handler.
+// CachedPattern is safe to use concurrently.
+type CachedPattern struct {
+	*Pattern
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	fsys    fs.FS
+	root    string
+	results []string
+	created time.Time
+}
+
+// NewCached creates a new CachedPattern from pat. Results are cached for ttl.
+// It returns an error indicating any invalid pattern.
+func NewCached(pat string, ttl time.Duration) (*CachedPattern, error) {
+	p, err := New(pat)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedPattern{
+		Pattern: p,
+		ttl:     ttl,
+		now:     time.Now,
+	}, nil
+}
+
+// GlobFS works like Pattern.GlobFS but returns a cached result if fsys and
+// root have been globbed within the TTL. The returned slice may be modified
+// by the caller.
+func (c *CachedPattern) GlobFS(fsys fs.FS, root string) ([]string, error) {
+	return c.GlobFSContext(context.Background(), fsys, root)
+}
+
+// GlobFSContext works like Pattern.GlobFSContext but returns a cached result
+// if fsys and root have been globbed within the TTL. Failed walks are not
+// cached.
+func (c *CachedPattern) GlobFSContext(ctx context.Context, fsys fs.FS, root string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.results != nil && c.root == root && sameFS(c.fsys, fsys) && c.now().Sub(c.created) < c.ttl {
+		return slices.Clone(c.results), nil
+	}
+
+	results, err := c.Pattern.GlobFSContext(ctx, fsys, root)
+	if err != nil {
+		return results, err
+	}
+
+	c.fsys = fsys
+	c.root = root
+	c.results = results
+	c.created = c.now()
+
+	return slices.Clone(results), nil
+}
+
+// Invalidate discards the cached result. The next call to GlobFS or
+// GlobFSContext walks the filesystem.
+func (c *CachedPattern) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fsys = nil
+	c.results = nil
+}
+
+// sameFS reports whether a and b are the same fs.FS. Filesystems whose
+// dynamic type is not comparable (such as fstest.MapFS) are never considered
+// the same.
+func sameFS(a, b fs.FS) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) || !ta.Comparable() {
+		return false
+	}
+
+	return a == b
+}
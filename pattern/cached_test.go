@@ -0,0 +1,64 @@
+package pattern
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+// countingFS counts the number of directories read.
+type countingFS struct {
+	*fsmock.FS
+	reads int
+}
+
+func (c *countingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	c.reads++
+	return c.FS.ReadDir(name)
+}
+
+func TestCachedPattern(t *testing.T) {
+	fsys := &countingFS{
+		FS: fsmock.New(fsmock.NewDir("",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("go.mod"),
+		)),
+	}
+
+	pat, err := NewCached("*.go", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	pat.now = func() time.Time { return now }
+
+	files, err := pat.GlobFSContext(context.Background(), fsys, "")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, files).Is(DeepEqual([]string{"main.go"}))
+
+	fsys.Touch("util.go")
+
+	files, err = pat.GlobFSContext(context.Background(), fsys, "")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, files).Is(DeepEqual([]string{"main.go"}))
+	ExpectThat(t, fsys.reads).Is(Equal(1))
+
+	now = now.Add(time.Minute)
+
+	files, err = pat.GlobFS(fsys, "")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, files).Is(DeepEqual([]string{"main.go", "util.go"}))
+	ExpectThat(t, fsys.reads).Is(Equal(2))
+
+	pat.Invalidate()
+
+	_, err = pat.GlobFS(fsys, "")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, fsys.reads).Is(Equal(3))
+}
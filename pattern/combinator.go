@@ -0,0 +1,113 @@
+package pattern
+
+// combinatorOp defines the operation applied by a combinator pattern.
+type combinatorOp int
+
+const (
+	// A regular pattern defined by its tokens
+	opNone combinatorOp = iota
+	// Matches if any of the patterns matches
+	opAny
+	// Matches if all of the patterns match
+	opAll
+	// Matches if the single pattern does not match
+	opNot
+)
+
+// Any returns a pattern that matches a path if any of patterns matches the
+// path. Any called without any pattern returns a pattern that never matches.
+// When called with a single pattern, that pattern is returned.
+//
+// Globbing with the returned pattern walks the filesystem once and returns
+// the union of the paths matched by patterns.
+func Any(patterns ...*Pattern) *Pattern {
+	if len(patterns) == 1 {
+		return patterns[0]
+	}
+
+	return &Pattern{
+		op:       opAny,
+		patterns: patterns,
+	}
+}
+
+// All returns a pattern that matches a path if all of patterns match the
+// path. All called without any pattern returns a pattern that matches every
+// path. When called with a single pattern, that pattern is returned.
+//
+// Globbing with the returned pattern walks the filesystem once and returns
+// the intersection of the paths matched by patterns.
+func All(patterns ...*Pattern) *Pattern {
+	if len(patterns) == 1 {
+		return patterns[0]
+	}
+
+	return &Pattern{
+		op:       opAll,
+		patterns: patterns,
+	}
+}
+
+// Not returns a pattern that matches a path if p does not match the path.
+// Combine it with All to exclude paths from another pattern, i.e.
+//
+//	pattern.All(pattern.MustNew("**/*.go"), pattern.Not(pattern.MustNew("**/*_test.go")))
+//
+// As it is unknown which files a directory contains, the returned pattern
+// may descend into any directory.
+func Not(p *Pattern) *Pattern {
+	return &Pattern{
+		op:       opNot,
+		patterns: []*Pattern{p},
+	}
+}
+
+// Difference returns a pattern that matches a path if a matches the path and
+// b does not. It is a shorthand for All(a, Not(b)), so globbing with the
+// returned pattern walks the filesystem once, descending only into the
+// directories a may match, and filters out the paths matched by b.
+func Difference(a, b *Pattern) *Pattern {
+	return All(a, Not(b))
+}
+
+// Intersection returns a pattern that matches a path if both a and b match
+// the path. It is a shorthand for All(a, b).
+func Intersection(a, b *Pattern) *Pattern {
+	return All(a, b)
+}
+
+// combine applies pat's combinator operation to the result of invoking fn for
+// each of pat's patterns. It short circuits as soon as the result is known.
+func (pat *Pattern) combine(fn func(*Pattern) bool) bool {
+	for _, p := range pat.patterns {
+		if fn(p) == (pat.op == opAny) {
+			return pat.op == opAny
+		}
+	}
+
+	return pat.op == opAll
+}
+
+func (pat *Pattern) matchCombinator(f string, stats *MatchStats) bool {
+	if pat.op == opNot {
+		return !pat.patterns[0].match(f, stats)
+	}
+
+	return pat.combine(func(p *Pattern) bool { return p.match(f, stats) })
+}
+
+func (pat *Pattern) hasRecursiveWildcardCombinator() bool {
+	if pat.op == opNot {
+		return pat.patterns[0].HasRecursiveWildcard()
+	}
+
+	return pat.combine((*Pattern).HasRecursiveWildcard)
+}
+
+func (pat *Pattern) canDescendCombinator(dir string) bool {
+	if pat.op == opNot {
+		return true
+	}
+
+	return pat.combine(func(p *Pattern) bool { return p.CanDescend(dir) })
+}
@@ -0,0 +1,170 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func mustNew(t *testing.T, pat string) *Pattern {
+	t.Helper()
+
+	p, err := New(pat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestAny(t *testing.T) {
+	goFiles := mustNew(t, "**/*.go")
+	mod := mustNew(t, "go.mod")
+
+	t.Run("empty", func(t *testing.T) {
+		p := Any()
+		ExpectThat(t, p.Match("main.go")).Is(Equal(false))
+		ExpectThat(t, p.Match("")).Is(Equal(false))
+		ExpectThat(t, p.HasRecursiveWildcard()).Is(Equal(false))
+		ExpectThat(t, p.CanDescend("cmd")).Is(Equal(false))
+	})
+
+	t.Run("single", func(t *testing.T) {
+		ExpectThat(t, Any(goFiles) == goFiles).Is(Equal(true))
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		p := Any(goFiles, mod)
+		ExpectThat(t, p.Match("cmd/main.go")).Is(Equal(true))
+		ExpectThat(t, p.Match("go.mod")).Is(Equal(true))
+		ExpectThat(t, p.Match("go.sum")).Is(Equal(false))
+		ExpectThat(t, p.HasRecursiveWildcard()).Is(Equal(true))
+		ExpectThat(t, p.CanDescend("cmd")).Is(Equal(true))
+		ExpectThat(t, Any(mod, mustNew(t, "go.sum")).CanDescend("cmd")).Is(Equal(false))
+	})
+}
+
+func TestAll(t *testing.T) {
+	goFiles := mustNew(t, "**/*.go")
+	cmd := mustNew(t, "cmd/*")
+
+	t.Run("empty", func(t *testing.T) {
+		p := All()
+		ExpectThat(t, p.Match("main.go")).Is(Equal(true))
+		ExpectThat(t, p.HasRecursiveWildcard()).Is(Equal(true))
+		ExpectThat(t, p.CanDescend("cmd")).Is(Equal(true))
+	})
+
+	t.Run("single", func(t *testing.T) {
+		ExpectThat(t, All(goFiles) == goFiles).Is(Equal(true))
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		p := All(goFiles, cmd)
+		ExpectThat(t, p.Match("cmd/main.go")).Is(Equal(true))
+		ExpectThat(t, p.Match("cmd/go.mod")).Is(Equal(false))
+		ExpectThat(t, p.Match("internal/tool.go")).Is(Equal(false))
+		ExpectThat(t, p.HasRecursiveWildcard()).Is(Equal(false))
+		ExpectThat(t, p.CanDescend("cmd")).Is(Equal(true))
+		ExpectThat(t, p.CanDescend("internal")).Is(Equal(false))
+	})
+}
+
+func TestNot(t *testing.T) {
+	goFiles := mustNew(t, "**/*.go")
+	tests := mustNew(t, "**/*_test.go")
+
+	p := All(goFiles, Not(tests))
+	ExpectThat(t, p.Match("cmd/main.go")).Is(Equal(true))
+	ExpectThat(t, p.Match("cmd/main_test.go")).Is(Equal(false))
+	ExpectThat(t, p.Match("go.mod")).Is(Equal(false))
+	ExpectThat(t, p.CanDescend("cmd")).Is(Equal(true))
+	ExpectThat(t, Not(mustNew(t, "cmd/*")).CanDescend("internal")).Is(Equal(true))
+	ExpectThat(t, p.Equal(All(goFiles, Not(tests)))).Is(Equal(true))
+	ExpectThat(t, Not(goFiles).GoString()).Is(Equal(`pattern.Not(pattern.MustNew("**/*.go"))`))
+}
+
+func TestCombinator_GlobFS(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("README.md"),
+		),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+		),
+	))
+
+	goFiles := mustNew(t, "**/*.go")
+	cmd := mustNew(t, "cmd/*")
+
+	union, err := Any(goFiles, cmd).GlobFS(fsys, "")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, union).Is(DeepEqual([]string{
+		"cmd/main.go",
+		"cmd/README.md",
+		"internal/tool.go",
+	}))
+
+	intersection, err := All(goFiles, cmd).GlobFS(fsys, "")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, intersection).Is(DeepEqual([]string{
+		"cmd/main.go",
+	}))
+}
+
+func TestDifference(t *testing.T) {
+	goFiles := mustNew(t, "**/*.go")
+	tests := mustNew(t, "**/*_test.go")
+	cmd := mustNew(t, "cmd/*")
+
+	paths := []string{"", "go.mod", "main.go", "cmd/main.go", "cmd/main_test.go", "internal/tool.go", "cmd/README.md"}
+
+	t.Run("identities", func(t *testing.T) {
+		for _, f := range paths {
+			ExpectThat(t, Difference(goFiles, goFiles).Match(f)).Is(Equal(false))
+			ExpectThat(t, Difference(goFiles, Any()).Match(f)).Is(Equal(goFiles.Match(f)))
+			ExpectThat(t, Difference(Any(), goFiles).Match(f)).Is(Equal(false))
+			ExpectThat(t, Intersection(goFiles, goFiles).Match(f)).Is(Equal(goFiles.Match(f)))
+			ExpectThat(t, Intersection(goFiles, cmd).Match(f)).Is(Equal(Intersection(cmd, goFiles).Match(f)))
+			ExpectThat(t, Intersection(goFiles, Any()).Match(f)).Is(Equal(false))
+			ExpectThat(t, Any(Difference(goFiles, cmd), Intersection(goFiles, cmd)).Match(f)).Is(Equal(goFiles.Match(f)))
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		p := Difference(goFiles, tests)
+		ExpectThat(t, p.Match("cmd/main.go")).Is(Equal(true))
+		ExpectThat(t, p.Match("cmd/main_test.go")).Is(Equal(false))
+		ExpectThat(t, p.Match("go.mod")).Is(Equal(false))
+		ExpectThat(t, Difference(cmd, goFiles).CanDescend("internal")).Is(Equal(false))
+	})
+
+	t.Run("GlobFS", func(t *testing.T) {
+		fsys := fsmock.New(fsmock.NewDir("",
+			fsmock.EmptyFile("go.mod"),
+			fsmock.NewDir("cmd",
+				fsmock.EmptyFile("main.go"),
+				fsmock.EmptyFile("main_test.go"),
+				fsmock.EmptyFile("README.md"),
+			),
+		))
+
+		files, err := Difference(cmd, tests).GlobFS(fsys, "")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, files).Is(DeepEqual([]string{
+			"cmd/main.go",
+			"cmd/README.md",
+		}))
+
+		files, err = Intersection(goFiles, cmd).GlobFS(fsys, "")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, files).Is(DeepEqual([]string{
+			"cmd/main.go",
+			"cmd/main_test.go",
+		}))
+	})
+}
@@ -0,0 +1,120 @@
+package pattern
+
+import (
+	"fmt"
+)
+
+// complexityWeights defines the weight of each wildcard token type when
+// computing a pattern's complexity. Literals do not add to the complexity.
+var complexityWeights = map[tokenType]int{
+	tokenTypeAnyDirectories: 3,
+	tokenTypeAnyRunes:       2,
+	tokenTypeSingleRune:     1,
+	tokenTypeGroup:          1,
+}
+
+// Complexity returns a measure of the matching effort pat may cause. It is
+// computed as the number of wildcards and groups weighted by their type:
+// a directory wildcard (**) counts 3, an any-runes wildcard (*) counts 2,
+// single rune wildcards (?) and groups count 1 each. Multiple wildcards may
+// cause the matcher to backtrack excessively, so patterns from untrusted
+// sources should be restricted using WithMaxComplexity. The complexity of a
+// pattern created using Any or All is the sum of its patterns' complexities.
+func (pat *Pattern) Complexity() int {
+	if pat.op != opNone {
+		c := 0
+		for _, p := range pat.patterns {
+			c += p.Complexity()
+		}
+		return c
+	}
+
+	c := 0
+	for _, t := range pat.tokens {
+		c += complexityWeights[t.t]
+	}
+	return c
+}
+
+// NumTokens returns the number of tokens pat has been parsed into. Each
+// literal rune, wildcard and group counts as a single token. The number of
+// tokens of a pattern created using Any, All or Not is the sum of its
+// patterns' number of tokens.
+func (pat *Pattern) NumTokens() int {
+	if pat.op != opNone {
+		n := 0
+		for _, p := range pat.patterns {
+			n += p.NumTokens()
+		}
+		return n
+	}
+
+	return len(pat.tokens)
+}
+
+// WithMaxComplexity causes New and NewWithOptions to reject patterns whose
+// complexity exceeds n with an error wrapping ErrBadPattern. See Complexity.
+// A limit of 20 accepts all common patterns such as **/*_test.go while
+// rejecting patterns chaining lots of wildcards. A value of zero (the
+// default) means unlimited.
+func WithMaxComplexity(n int) Option {
+	return func(p *Pattern) {
+		p.maxComplexity = n
+	}
+}
+
+// checkComplexity returns an error if pat exceeds its maximum complexity.
+func (pat *Pattern) checkComplexity() error {
+	if pat.maxComplexity <= 0 {
+		return nil
+	}
+
+	if c := pat.Complexity(); c > pat.maxComplexity {
+		return fmt.Errorf("%w: complexity %d exceeds maximum of %d", ErrBadPattern, c, pat.maxComplexity)
+	}
+
+	return nil
+}
+
+// patternDepth returns the number of directories a path matched by pat
+// contains, i.e. the number of separators in pat. It returns -1 if the
+// depth is unlimited because pat contains a directory wildcard.
+func (pat *Pattern) patternDepth() int {
+	switch pat.op {
+	case opNot:
+		return -1
+
+	case opAll:
+		// A path matched by all patterns is no deeper than the shallowest
+		// pattern with a limited depth.
+		depth := -1
+		for _, p := range pat.patterns {
+			if d := p.patternDepth(); d >= 0 && (depth < 0 || d < depth) {
+				depth = d
+			}
+		}
+		return depth
+
+	case opAny:
+		depth := 0
+		for _, p := range pat.patterns {
+			d := p.patternDepth()
+			if d < 0 {
+				return -1
+			}
+			depth = max(depth, d)
+		}
+		return depth
+	}
+
+	depth := 0
+	for _, t := range pat.tokens {
+		switch {
+		case t.t == tokenTypeAnyDirectories:
+			return -1
+		case t.t == tokenTypeLiteral && t.r == Separator:
+			depth++
+		}
+	}
+	return depth
+}
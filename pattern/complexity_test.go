@@ -0,0 +1,50 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_Complexity(t *testing.T) {
+	tests := map[string]int{
+		"main.go":              0,
+		"*.go":                 2,
+		"?.go":                 1,
+		"[ab].go":              1,
+		"**/*.go":              5,
+		"**/**/**/*.go":        11,
+		"**/**/**/**/**/*.go":  17,
+		"src/**/[a-f]?o*_x.go": 7,
+	}
+
+	for pat, want := range tests {
+		ExpectThat(t, MustNew(pat).Complexity()).Is(Equal(want))
+	}
+
+	ExpectThat(t, MustNew("**/**/**/*.go").Complexity() > MustNew("**/*.go").Complexity()).Is(Equal(true))
+	ExpectThat(t, Any(MustNew("**/*.go"), MustNew("*.md")).Complexity()).Is(Equal(7))
+}
+
+func TestPattern_NumTokens(t *testing.T) {
+	tests := map[string]int{
+		"main.go": 7,
+		"*.go":    4,
+		"[ab].go": 4,
+		"**/*.go": 6,
+	}
+
+	for pat, want := range tests {
+		ExpectThat(t, MustNew(pat).NumTokens()).Is(Equal(want))
+	}
+
+	ExpectThat(t, Any(MustNew("**/*.go"), MustNew("*.md")).NumTokens()).Is(Equal(10))
+}
+
+func TestNewWithOptions_WithMaxComplexity(t *testing.T) {
+	_, err := NewWithOptions("**/*.go", WithMaxComplexity(5))
+	ExpectThat(t, err).Is(NoError())
+
+	_, err = NewWithOptions("**/**/**/*.go", WithMaxComplexity(5))
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+}
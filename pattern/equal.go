@@ -0,0 +1,46 @@
+package pattern
+
+import (
+	"slices"
+	"strings"
+)
+
+// Equal reports whether pat and other are equal. Patterns are compared
+// structurally after parsing, so patterns that differ only in escaping
+// characters without a special meaning (i.e. "a.go" and "a\.go") are equal.
+// If Equal returns true, both patterns match exactly the same set of paths.
+// The opposite is not guaranteed: patterns such as "[ab]" and "[ba]" match
+// the same paths but are not considered equal.
+func (pat *Pattern) Equal(other *Pattern) bool {
+	if pat == other {
+		return true
+	}
+
+	if pat == nil || other == nil {
+		return false
+	}
+
+	if pat.op != other.op || pat.maxDepth != other.maxDepth {
+		return false
+	}
+
+	if pat.windowsPaths != other.windowsPaths || !strings.EqualFold(pat.drive, other.drive) {
+		return false
+	}
+
+	if pat.op != opNone {
+		return slices.EqualFunc(pat.patterns, other.patterns, (*Pattern).Equal)
+	}
+
+	return slices.EqualFunc(pat.tokens, other.tokens, token.equal)
+}
+
+// equal reports whether t and o are equal.
+func (t token) equal(o token) bool {
+	return t.t == o.t &&
+		t.r == o.r &&
+		t.g.neg == o.g.neg &&
+		t.g.fold == o.g.fold &&
+		slices.Equal(t.g.runes, o.g.runes) &&
+		slices.Equal(t.g.ranges, o.g.ranges)
+}
@@ -0,0 +1,68 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_Equal(t *testing.T) {
+	tests := []struct {
+		a, b *Pattern
+		want bool
+	}{
+		{MustNew("**/*.go"), MustNew("**/*.go"), true},
+		{MustNew("a.go"), MustNew(`a\.go`), true},
+		{MustNew("[a-c]?.go"), MustNew(`[a-c]?.\go`), true},
+		{MustNew("a*.go"), MustNew(`a\*.go`), false},
+		{MustNew("a?.go"), MustNew(`a\?.go`), false},
+		{MustNew("[a-c].go"), MustNew("[^a-c].go"), false},
+		{MustNew("[ab].go"), MustNew("[a-b].go"), false},
+		{MustNew("**/*.go"), MustNew("*/*.go"), false},
+		{MustNew("**/*.go"), MustNewWithOptions("**/*.go", WithMaxDepth(1)), false},
+		{Any(MustNew("*.go"), MustNew("*.md")), Any(MustNew("*.go"), MustNew("*.md")), true},
+		{Any(MustNew("*.go"), MustNew("*.md")), All(MustNew("*.go"), MustNew("*.md")), false},
+		{Any(MustNew("*.go"), MustNew("*.md")), Any(MustNew("*.md"), MustNew("*.go")), false},
+		{Any(MustNew("*.go"), MustNew("*.md")), MustNew("*.go"), false},
+	}
+
+	for _, test := range tests {
+		ExpectThat(t, test.a.Equal(test.b)).Is(Equal(test.want))
+		ExpectThat(t, test.b.Equal(test.a)).Is(Equal(test.want))
+	}
+}
+
+func TestPattern_Equal_windowsPaths(t *testing.T) {
+	defer func(w bool) { isWindows = w }(isWindows)
+	isWindows = true
+
+	tests := []struct {
+		a, b *Pattern
+		want bool
+	}{
+		{MustNewWithOptions("C:a/*", WithWindowsPaths()), MustNewWithOptions("C:a/*", WithWindowsPaths()), true},
+		{MustNewWithOptions("C:a/*", WithWindowsPaths()), MustNewWithOptions("c:a/*", WithWindowsPaths()), true},
+		{MustNewWithOptions("C:a/*", WithWindowsPaths()), MustNewWithOptions("D:a/*", WithWindowsPaths()), false},
+		{MustNewWithOptions("C:a/*", WithWindowsPaths()), MustNewWithOptions("a/*", WithWindowsPaths()), false},
+		{MustNewWithOptions("a/*", WithWindowsPaths()), MustNew("a/*"), false},
+	}
+
+	for _, test := range tests {
+		ExpectThat(t, test.a.Equal(test.b)).Is(Equal(test.want))
+		ExpectThat(t, test.b.Equal(test.a)).Is(Equal(test.want))
+	}
+}
+
+func TestPattern_Equal_semantics(t *testing.T) {
+	paths := []string{"a.go", "ab.go", `a\.go`, "a*.go"}
+
+	// a.go and a\.go are semantically equal as . has no special meaning.
+	plain, escaped := MustNew("a.go"), MustNew(`a\.go`)
+	for _, p := range paths {
+		ExpectThat(t, escaped.Match(p)).Is(Equal(plain.Match(p)))
+	}
+
+	// a*.go and a\*.go are semantically different.
+	ExpectThat(t, MustNew("a*.go").Match("ab.go")).Is(Equal(true))
+	ExpectThat(t, MustNew(`a\*.go`).Match("ab.go")).Is(Equal(false))
+}
@@ -0,0 +1,35 @@
+package pattern
+
+import "unicode"
+
+// WithUnicodeFolding causes groups to match runes case-insensitively using
+// Unicode simple case folding. A rune is matched by a group if the rune
+// itself or any rune it folds to (see unicode.SimpleFold) is contained in
+// the group, i.e. [a-z] matches A to Z and [α-ω] matches Α to Ω. Runes
+// outside of groups are still matched case-sensitively.
+func WithUnicodeFolding() Option {
+	return func(p *Pattern) {
+		p.unicodeFolding = true
+	}
+}
+
+// foldGroups enables case folding for all groups contained in tokens.
+func foldGroups(tokens []token) {
+	for i := range tokens {
+		if tokens[i].t == tokenTypeGroup {
+			tokens[i].g.fold = true
+		}
+	}
+}
+
+// matchFolded reports whether any rune r folds to is contained in g. r
+// itself is not checked.
+func (g runeGroup) matchFolded(r rune) bool {
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if g.contains(f) {
+			return true
+		}
+	}
+
+	return false
+}
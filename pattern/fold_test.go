@@ -0,0 +1,55 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestNewWithOptions_WithUnicodeFolding(t *testing.T) {
+	tests := []struct {
+		pattern, f string
+		match      bool
+	}{
+		{"[a-z].go", "a.go", true},
+		{"[a-z].go", "A.go", true},
+		{"[a-z].go", "Z.go", true},
+		{"[a-z].go", "1.go", false},
+		{"[A-Z].go", "q.go", true},
+		{"[abc].go", "B.go", true},
+		{"[^a-z].go", "A.go", false},
+		{"[^a-z].go", "1.go", true},
+		{"[α-ω].go", "λ.go", true},
+		{"[α-ω].go", "Λ.go", true},
+		{"[α-ω].go", "a.go", false},
+		{"[a-z]", "K", true},
+		// U+212A KELVIN SIGN folds to k and K
+		{"[a-z]", "K", true},
+		// Runes outside of groups are matched case sensitively.
+		{"a[a-z].go", "AB.go", false},
+		{"a[a-z].go", "aB.go", true},
+	}
+
+	for _, tt := range tests {
+		pat := MustNewWithOptions(tt.pattern, WithUnicodeFolding())
+		if got := pat.Match(tt.f); got != tt.match {
+			t.Errorf("NewWithOptions(%#q, WithUnicodeFolding()).Match(%#q): wanted %v but got %v", tt.pattern, tt.f, tt.match, got)
+		}
+
+		re := pat.ToRegexp()
+		if got := re.MatchString(tt.f); got != tt.match {
+			t.Errorf("NewWithOptions(%#q, WithUnicodeFolding()).ToRegexp() = %s: MatchString(%#q) = %v", tt.pattern, re, tt.f, got)
+		}
+	}
+
+	ExpectThat(t, MustNew("[a-z].go").Match("A.go")).Is(Equal(false))
+	ExpectThat(t, MustNew("[α-ω].go").Match("Λ.go")).Is(Equal(false))
+}
+
+func TestNewWithOptions_WithUnicodeFolding_equal(t *testing.T) {
+	folded := MustNewWithOptions("[a-z].go", WithUnicodeFolding())
+
+	ExpectThat(t, folded.Equal(MustNewWithOptions("[a-z].go", WithUnicodeFolding()))).Is(Equal(true))
+	ExpectThat(t, folded.Equal(MustNew("[a-z].go"))).Is(Equal(false))
+	ExpectThat(t, folded.GoString()).Is(Equal(`pattern.MustNewWithOptions("[a-z].go", pattern.WithUnicodeFolding())`))
+}
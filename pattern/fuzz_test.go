@@ -0,0 +1,82 @@
+package pattern
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/halimath/fsmock"
+)
+
+func FuzzNew(f *testing.F) {
+	for _, tt := range tests {
+		f.Add(tt.pattern)
+	}
+
+	f.Fuzz(func(t *testing.T, pat string) {
+		p, err := New(pat)
+		if err != nil {
+			if !errors.Is(err, ErrBadPattern) {
+				t.Errorf("New(%q) returned error not wrapping ErrBadPattern: %v", pat, err)
+			}
+			return
+		}
+
+		if p == nil {
+			t.Errorf("New(%q) returned nil pattern without an error", pat)
+		}
+	})
+}
+
+func FuzzMatch(f *testing.F) {
+	for _, tt := range tests {
+		f.Add(tt.pattern, tt.f)
+	}
+
+	f.Fuzz(func(t *testing.T, pat, name string) {
+		p, err := New(pat)
+		if err != nil {
+			return
+		}
+
+		p.Match(name)
+	})
+}
+
+func FuzzGlobFS(f *testing.F) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.EmptyFile("main.go"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+		fsmock.NewDir("internal",
+			fsmock.NewDir("tool",
+				fsmock.EmptyFile("tool.go"),
+				fsmock.EmptyFile("tool_test.go"),
+			),
+		),
+	))
+
+	for _, tt := range tests {
+		f.Add(tt.pattern)
+	}
+
+	f.Fuzz(func(t *testing.T, pat string) {
+		p, err := New(pat)
+		if err != nil {
+			return
+		}
+
+		files, err := p.GlobFS(fsys, "")
+		if err != nil {
+			t.Fatalf("GlobFS(%q) failed: %v", pat, err)
+		}
+
+		for _, file := range files {
+			if !p.Match(file) {
+				t.Errorf("GlobFS(%q) returned %q which does not match", pat, file)
+			}
+		}
+	})
+}
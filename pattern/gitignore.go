@@ -0,0 +1,128 @@
+package pattern
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewGitignore creates a new pattern from a single line of a .gitignore file
+// and returns it. The returned pattern matches the path names of all files
+// git would ignore due to pat:
+//
+//   - A pattern without a separator (except for a trailing one) matches at
+//     any level below the root; otherwise it is relative to the root. A
+//     leading separator only anchors the pattern.
+//   - A pattern ending with a separator only matches directories. As
+//     patterns match file names, such a pattern matches all files contained
+//     in a matching directory.
+//   - A pattern without a trailing separator matches files as well as all
+//     files contained in a matching directory.
+//   - A leading **/ matches in all directories, a trailing /** matches
+//     everything inside and /**/ matches zero or more directories. Other
+//     consecutive asterisks are treated as a single *.
+//   - A group may be negated using ! in addition to ^, i.e. [!a-z].
+//   - Trailing spaces are ignored unless escaped with a backslash.
+//
+// Negating patterns (starting with !) cannot be expressed as a single
+// pattern and cause NewGitignore to return an error; use Not to exclude
+// files matched by another pattern. Blank patterns and comments (starting
+// with #) are rejected, too.
+func NewGitignore(pat string) (*Pattern, error) {
+	p := trimTrailingSpaces(pat)
+
+	if p == "" || strings.HasPrefix(p, "#") {
+		return nil, fmt.Errorf("%w: blank gitignore pattern or comment: %q", ErrBadPattern, pat)
+	}
+
+	if strings.HasPrefix(p, "!") {
+		return nil, fmt.Errorf("%w: negated gitignore pattern not supported: %q", ErrBadPattern, pat)
+	}
+
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		switch {
+		case s == "**" && i == len(segments)-1:
+			// A trailing /** matches everything inside.
+			segments[i] = "**/*"
+		case s == "**":
+			// Followed by a separator, ** matches zero or more directories.
+		default:
+			segments[i] = translateGitignoreSegment(s)
+		}
+	}
+	p = strings.Join(segments, "/")
+
+	if !anchored && !strings.HasPrefix(p, "**/") {
+		p = "**/" + p
+	}
+
+	contents := p + "/**/*"
+	if dirOnly {
+		return New(contents)
+	}
+
+	if strings.HasSuffix(p, "**/*") {
+		// Already matches everything inside.
+		return New(p)
+	}
+
+	files, err := New(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return Any(files, MustNew(contents)), nil
+}
+
+// trimTrailingSpaces removes trailing spaces from p unless they are escaped
+// with a backslash.
+func trimTrailingSpaces(p string) string {
+	for strings.HasSuffix(p, " ") && !strings.HasSuffix(p, `\ `) {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+// translateGitignoreSegment translates a single path segment (not being **)
+// from gitignore syntax to pattern syntax. It collapses consecutive
+// asterisks into a single one and translates group negation using !.
+func translateGitignoreSegment(s string) string {
+	var b strings.Builder
+
+	// The previous rune unless it has been escaped
+	var prev rune
+	escaped := false
+
+	for _, r := range s {
+		if escaped {
+			b.WriteRune(r)
+			escaped = false
+			prev = 0
+			continue
+		}
+
+		switch r {
+		case Backslash:
+			escaped = true
+		case AnyWildcard:
+			if prev == AnyWildcard {
+				continue
+			}
+		case '!':
+			if prev == GroupStart {
+				r = GroupNegate
+			}
+		}
+
+		b.WriteRune(r)
+		prev = r
+	}
+
+	return b.String()
+}
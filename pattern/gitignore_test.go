@@ -0,0 +1,114 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+// The test cases are derived from the examples given in
+// https://git-scm.com/docs/gitignore.
+var gitignoreTests = []struct {
+	pattern string
+	f       string
+	match   bool
+}{
+	// A pattern without a separator matches at any level.
+	{"hello.*", "hello.c", true},
+	{"hello.*", "src/hello.h", true},
+	{"hello.*", "src/hello", false},
+	{"*.txt", "notes.txt", true},
+	{"*.txt", "doc/notes.txt", true},
+
+	// A name matches files as well as directories.
+	{"foo", "foo", true},
+	{"foo", "a/foo", true},
+	{"foo", "foo/bar.c", true},
+	{"foo", "a/foo/b/bar.c", true},
+	{"foo", "foobar", false},
+
+	// A leading or middle separator anchors the pattern.
+	{"/foo", "foo", true},
+	{"/foo", "a/foo", false},
+	{"/foo", "foo/bar.c", true},
+	{"doc/*.txt", "doc/notes.txt", true},
+	{"doc/*.txt", "doc/server/arch.txt", false},
+	{"doc/*.txt", "a/doc/notes.txt", false},
+	{"foo/*", "foo/test.json", true},
+	{"foo/*", "foo/bar/hello.c", true},
+	{"foo/*", "foo", false},
+
+	// A trailing separator only matches directories.
+	{"frotz/", "frotz/a.c", true},
+	{"frotz/", "a/frotz/a.c", true},
+	{"frotz/", "frotz", false},
+	{"doc/frotz/", "doc/frotz/a.c", true},
+	{"doc/frotz/", "a/doc/frotz/a.c", false},
+
+	// Leading **/
+	{"**/foo", "foo", true},
+	{"**/foo", "a/b/foo", true},
+	{"**/foo/bar", "foo/bar", true},
+	{"**/foo/bar", "x/foo/bar", true},
+	{"**/foo/bar", "x/foo/baz", false},
+
+	// Trailing /**
+	{"abc/**", "abc/x", true},
+	{"abc/**", "abc/x/y", true},
+	{"abc/**", "abc", false},
+	{"abc/**", "x/abc/y", false},
+	{"**", "a/b/c", true},
+
+	// /**/ matches zero or more directories
+	{"a/**/b", "a/b", true},
+	{"a/**/b", "a/x/b", true},
+	{"a/**/b", "a/x/y/b", true},
+	{"a/**/b", "a/x/c", false},
+
+	// Other consecutive asterisks are regular asterisks.
+	{"a***b", "axyb", true},
+	{"a***b", "ax/yb", false},
+
+	// Groups and escapes
+	{"[!a]*.txt", "b.txt", true},
+	{"[!a]*.txt", "a.txt", false},
+	{"[^a]*.txt", "a.txt", false},
+	{`\#file`, "#file", true},
+	{`\!important`, "!important", true},
+	{`\*.go`, "*.go", true},
+	{`\*.go`, "main.go", false},
+
+	// Trailing spaces
+	{"foo   ", "foo", true},
+	{`foo\ `, "foo ", true},
+	{`foo\ `, "foo", false},
+}
+
+func TestNewGitignore(t *testing.T) {
+	for _, tt := range gitignoreTests {
+		pat, err := NewGitignore(tt.pattern)
+		if err != nil {
+			t.Errorf("NewGitignore(%#q): unexpected error: %v", tt.pattern, err)
+			continue
+		}
+
+		if got := pat.Match(tt.f); got != tt.match {
+			t.Errorf("NewGitignore(%#q).Match(%#q): wanted match %v but got %v", tt.pattern, tt.f, tt.match, got)
+		}
+	}
+}
+
+func TestNewGitignore_invalid(t *testing.T) {
+	for _, pat := range []string{"", "   ", "# comment", "!foo", "[a-"} {
+		_, err := NewGitignore(pat)
+		ExpectThat(t, err).Is(Error(ErrBadPattern))
+	}
+}
+
+func TestNewGitignore_doesNotAffectNew(t *testing.T) {
+	pat := MustNew("foo")
+	ExpectThat(t, pat.Match("a/foo")).Is(Equal(false))
+
+	_, err := New("a***b")
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+}
@@ -0,0 +1,104 @@
+package pattern
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_patternDepth(t *testing.T) {
+	tests := map[*Pattern]int{
+		MustNew("main.go"):                         0,
+		MustNew("cmd/*.go"):                        1,
+		MustNew("*/*/[a-z]?.go"):                   2,
+		MustNew("src/**/*.go"):                     -1,
+		MustNew("src/**"):                          -1,
+		Any(MustNew("*.go"), MustNew("a/b/*.go")):  2,
+		Any(MustNew("*.go"), MustNew("**/*.md")):   -1,
+		Not(MustNew("*.go")):                       -1,
+		All():                                      -1,
+		All(MustNew("**/*.go"), MustNew("a/*.go")): 1,
+		Difference(MustNew("a/*/*.go"), MustNew("**/*_test.go")): 2,
+	}
+
+	for pat, want := range tests {
+		ExpectThat(t, pat.patternDepth()).Is(Equal(want))
+	}
+}
+
+func TestPattern_GlobFSDepth(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.NewDir("a",
+			fsmock.EmptyFile("a.go"),
+			fsmock.NewDir("b",
+				fsmock.EmptyFile("b.go"),
+				fsmock.NewDir("c",
+					fsmock.EmptyFile("c.go"),
+				),
+			),
+		),
+	))
+
+	tests := map[int][]string{
+		-1: {"main.go", "a/a.go", "a/b/b.go", "a/b/c/c.go"},
+		0:  {"main.go"},
+		2:  {"main.go", "a/a.go", "a/b/b.go"},
+	}
+
+	for depth, want := range tests {
+		got, err := MustNew("**/*.go").GlobFSDepth(fsys, ".", depth)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, got).Is(DeepEqual(want))
+	}
+
+	got, err := MustNew("*/*.go").GlobFS(fsys, ".", WithPruner(nil))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(DeepEqual([]string{"a/a.go"}))
+}
+
+// deepFS creates a filesystem with a binary tree of directories levels deep.
+// Each directory contains two files.
+func deepFS(levels int) *fsmock.FS {
+	var dir func(name string, level int) *fsmock.Dir
+	dir = func(name string, level int) *fsmock.Dir {
+		entries := []fsmock.Entry{
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("README.md"),
+		}
+
+		if level < levels {
+			for i := 0; i < 2; i++ {
+				entries = append(entries, dir(fmt.Sprintf("dir_%d", i), level+1))
+			}
+		}
+
+		return fsmock.NewDir(name, entries...)
+	}
+
+	return fsmock.New(dir("", 0))
+}
+
+func BenchmarkGlobFSDepth(b *testing.B) {
+	fsys := deepFS(10)
+
+	benchmarks := map[string]func() ([]string, error){
+		"shallow":             func() ([]string, error) { return MustNew("*/*/*.go").GlobFS(fsys, ".") },
+		"shallow_unlimited":   func() ([]string, error) { return MustNew("*/*/*.go").GlobFSDepth(fsys, ".", -1) },
+		"recursive_depth":     func() ([]string, error) { return MustNew("**/*.go").GlobFSDepth(fsys, ".", 2) },
+		"recursive_unlimited": func() ([]string, error) { return MustNew("**/*.go").GlobFSDepth(fsys, ".", -1) },
+	}
+
+	for name, glob := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := glob(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
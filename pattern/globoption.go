@@ -0,0 +1,38 @@
+package pattern
+
+import "io/fs"
+
+// ShouldDescend defines a function that decides whether GlobFS descends into
+// the directory dir. dir is relative to the root passed to GlobFS and d is the
+// entry as reported by fs.WalkDir. Returning false skips dir and everything
+// below it.
+type ShouldDescend func(dir string, d fs.DirEntry) bool
+
+// GlobOption defines a function that customizes a single call to GlobFS.
+type GlobOption func(*globOptions)
+
+// globOptions collects the GlobOptions passed to GlobFS.
+type globOptions struct {
+	prune  ShouldDescend
+	sorted bool
+}
+
+// WithPruner sets fn to be invoked for every directory GlobFS is about to
+// descend into. This enables pruning directories beyond what CanDescend
+// decides based on the pattern, e.g. to skip vendor directories regardless
+// of the pattern. fn is only invoked for directories the pattern may contain
+// matches in.
+func WithPruner(fn ShouldDescend) GlobOption {
+	return func(o *globOptions) {
+		o.prune = fn
+	}
+}
+
+// WithSortedResults causes GlobFS to sort the matching path names
+// lexicographically. By default, path names are returned in the order
+// fs.WalkDir visits them which depends on the filesystem implementation.
+func WithSortedResults() GlobOption {
+	return func(o *globOptions) {
+		o.sorted = true
+	}
+}
@@ -0,0 +1,55 @@
+package pattern
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GoString returns a Go expression that reconstructs pat when compiled, i.e.
+// pattern.MustNew("**/*.go"). It implements fmt.GoStringer so pat can be
+// formatted using the %#v verb.
+func (pat *Pattern) GoString() string {
+	switch pat.op {
+	case opAny:
+		return pat.goStringCombinator("Any")
+	case opAll:
+		return pat.goStringCombinator("All")
+	case opNot:
+		return pat.goStringCombinator("Not")
+	}
+
+	var opts []string
+	if pat.maxDepth > 0 {
+		opts = append(opts, fmt.Sprintf("pattern.WithMaxDepth(%d)", pat.maxDepth))
+	}
+	if pat.unicodeFolding {
+		opts = append(opts, "pattern.WithUnicodeFolding()")
+	}
+	if pat.windowsPaths {
+		opts = append(opts, "pattern.WithWindowsPaths()")
+	}
+
+	if len(opts) > 0 {
+		return fmt.Sprintf("pattern.MustNewWithOptions(%s, %s)", strconv.Quote(pat.source), strings.Join(opts, ", "))
+	}
+
+	return fmt.Sprintf("pattern.MustNew(%s)", strconv.Quote(pat.source))
+}
+
+func (pat *Pattern) goStringCombinator(name string) string {
+	var b strings.Builder
+
+	b.WriteString("pattern.")
+	b.WriteString(name)
+	b.WriteRune('(')
+	for i, p := range pat.patterns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(p.GoString())
+	}
+	b.WriteRune(')')
+
+	return b.String()
+}
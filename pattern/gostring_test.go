@@ -0,0 +1,133 @@
+package pattern
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"strconv"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_GoString(t *testing.T) {
+	paths := []string{
+		"main.go",
+		"cmd/main.go",
+		"cmd/main_test.go",
+		"internal/tool/tool_test.go",
+		"a/b/c/d/e_test.go",
+		"README.md",
+		`a"b.go`,
+	}
+
+	tests := map[string]*Pattern{
+		`pattern.MustNew("**/*_test.go")`:                                     MustNew("**/*_test.go"),
+		`pattern.MustNew("a\"b.go")`:                                          MustNew(`a"b.go`),
+		`pattern.MustNew("[a-c]*.\\*")`:                                       MustNew(`[a-c]*.\*`),
+		`pattern.MustNewWithOptions("**/*_test.go", pattern.WithMaxDepth(2))`: MustNewWithOptions("**/*_test.go", WithMaxDepth(2)),
+		`pattern.Any(pattern.MustNew("*.go"), pattern.All(pattern.MustNew("**/*.go"), pattern.MustNew("cmd/**/*")))`: Any(
+			MustNew("*.go"),
+			All(MustNew("**/*.go"), MustNew("cmd/**/*")),
+		),
+	}
+
+	for want, pat := range tests {
+		got := fmt.Sprintf("%#v", pat)
+		ExpectThat(t, got).Is(Equal(want))
+
+		expr, err := parser.ParseExpr(got)
+		if err != nil {
+			t.Fatalf("%s: not a valid go expression: %s", got, err)
+		}
+
+		reconstructed := evalGoString(t, expr)
+		for _, p := range paths {
+			if reconstructed.Match(p) != pat.Match(p) {
+				t.Errorf("%s: reconstructed pattern differs when matching %q", got, p)
+			}
+		}
+	}
+}
+
+func TestPattern_GoString_windowsPaths(t *testing.T) {
+	defer func(w bool) { isWindows = w }(isWindows)
+	isWindows = true
+
+	pat := MustNewWithOptions("C:/**/*.go", WithWindowsPaths())
+	got := fmt.Sprintf("%#v", pat)
+	ExpectThat(t, got).Is(Equal(`pattern.MustNewWithOptions("C:/**/*.go", pattern.WithWindowsPaths())`))
+
+	expr, err := parser.ParseExpr(got)
+	if err != nil {
+		t.Fatalf("%s: not a valid go expression: %s", got, err)
+	}
+
+	ExpectThat(t, evalGoString(t, expr).Equal(pat)).Is(Equal(true))
+}
+
+// evalGoString evaluates expr as produced by GoString.
+func evalGoString(t *testing.T, expr ast.Expr) *Pattern {
+	t.Helper()
+
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		t.Fatalf("unexpected expression: %#v", expr)
+	}
+
+	switch call.Fun.(*ast.SelectorExpr).Sel.Name {
+	case "MustNew":
+		return MustNew(evalString(t, call.Args[0]))
+
+	case "MustNewWithOptions":
+		opts := make([]Option, 0, len(call.Args)-1)
+		for _, arg := range call.Args[1:] {
+			opt := arg.(*ast.CallExpr)
+			switch opt.Fun.(*ast.SelectorExpr).Sel.Name {
+			case "WithMaxDepth":
+				n, err := strconv.Atoi(opt.Args[0].(*ast.BasicLit).Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts = append(opts, WithMaxDepth(n))
+			case "WithUnicodeFolding":
+				opts = append(opts, WithUnicodeFolding())
+			case "WithWindowsPaths":
+				opts = append(opts, WithWindowsPaths())
+			default:
+				t.Fatalf("unexpected option: %#v", opt.Fun)
+			}
+		}
+		return MustNewWithOptions(evalString(t, call.Args[0]), opts...)
+
+	case "Any", "All":
+		patterns := make([]*Pattern, 0, len(call.Args))
+		for _, arg := range call.Args {
+			patterns = append(patterns, evalGoString(t, arg))
+		}
+		if call.Fun.(*ast.SelectorExpr).Sel.Name == "Any" {
+			return Any(patterns...)
+		}
+		return All(patterns...)
+	}
+
+	t.Fatalf("unexpected function: %#v", call.Fun)
+	return nil
+}
+
+func evalString(t *testing.T, expr ast.Expr) string {
+	t.Helper()
+
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != gotoken.STRING {
+		t.Fatalf("expected string literal: %#v", expr)
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
@@ -0,0 +1,142 @@
+package pattern
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Codes of the warnings reported by Lint.
+const (
+	// LintInvalid reports a pattern that cannot be parsed.
+	LintInvalid = "invalid"
+	// LintRecursiveWildcardSegment reports a ** that does not form a
+	// complete path segment.
+	LintRecursiveWildcardSegment = "recursive-wildcard-segment"
+	// LintOverlyBroad reports a pattern matching every file.
+	LintOverlyBroad = "overly-broad"
+	// LintHighComplexity reports a pattern whose complexity exceeds
+	// LintMaxComplexity.
+	LintHighComplexity = "high-complexity"
+)
+
+// LintMaxComplexity is the complexity above which Lint reports a pattern.
+// See Complexity.
+const LintMaxComplexity = 20
+
+// Warning describes a common mistake found in a pattern by Lint.
+type Warning struct {
+	// Code identifies the kind of mistake; see the Lint* constants.
+	Code string
+	// Message describes the mistake.
+	Message string
+	// Suggestion describes how to correct the mistake.
+	Suggestion string
+}
+
+// String returns a human readable representation of w.
+func (w Warning) String() string {
+	if w.Suggestion == "" {
+		return fmt.Sprintf("%s: %s", w.Code, w.Message)
+	}
+	return fmt.Sprintf("%s: %s (%s)", w.Code, w.Message, w.Suggestion)
+}
+
+// Lint checks pat for common mistakes and returns a warning for each mistake
+// found. It returns an empty slice if pat looks fine. Mistakes which cause
+// New to reject pat, such as a ** not forming a complete path segment, are
+// reported with a dedicated warning explaining the mistake; any other reason
+// to reject pat is reported as a warning with code LintInvalid.
+//
+// A trailing /** without a file pattern is not reported: unlike in some other
+// glob dialects, a trailing ** matches files at any depth, so "src/**" matches
+// exactly the same paths as "src/**/*".
+func Lint(pat string) []Warning {
+	warnings := make([]Warning, 0)
+
+	if pat == "**" || pat == "**/*" {
+		warnings = append(warnings, Warning{
+			Code:       LintOverlyBroad,
+			Message:    fmt.Sprintf("%q matches every file", pat),
+			Suggestion: `restrict the pattern to the files of interest, e.g. "**/*.go"`,
+		})
+	}
+
+	for _, r := range recursiveWildcards(pat) {
+		before := r.start == 0 || pat[r.start-1] == Separator
+		after := r.end == len(pat) || pat[r.end] == Separator
+
+		if before && after {
+			continue
+		}
+
+		replacement := "**"
+		if !before {
+			replacement = "*/" + replacement
+		}
+		if !after {
+			replacement += "/*"
+		}
+
+		warnings = append(warnings, Warning{
+			Code:       LintRecursiveWildcardSegment,
+			Message:    fmt.Sprintf("%s in %q does not form a complete path segment", pat[r.start:r.end], pat),
+			Suggestion: fmt.Sprintf("use %q", pat[:r.start]+replacement+pat[r.end:]),
+		})
+	}
+
+	p, err := New(pat)
+	if err != nil {
+		if len(warnings) == 0 {
+			warnings = append(warnings, Warning{
+				Code:    LintInvalid,
+				Message: err.Error(),
+			})
+		}
+		return warnings
+	}
+
+	if c := p.Complexity(); c > LintMaxComplexity {
+		warnings = append(warnings, Warning{
+			Code:       LintHighComplexity,
+			Message:    fmt.Sprintf("%q has a complexity of %d which exceeds %d", pat, c, LintMaxComplexity),
+			Suggestion: "reduce the number of wildcards",
+		})
+	}
+
+	return warnings
+}
+
+// span defines a range of bytes within a pattern.
+type span struct {
+	start, end int
+}
+
+// recursiveWildcards returns the spans of all runs of two or more unescaped
+// asterisks outside of groups in pat.
+func recursiveWildcards(pat string) []span {
+	var spans []span
+
+	for i := 0; i < len(pat); i++ {
+		switch pat[i] {
+		case Backslash:
+			i++
+
+		case GroupStart:
+			if j := strings.IndexByte(pat[i+1:], GroupEnd); j >= 0 {
+				i += j + 1
+			}
+
+		case AnyWildcard:
+			j := i
+			for j < len(pat) && pat[j] == AnyWildcard {
+				j++
+			}
+			if j-i > 1 {
+				spans = append(spans, span{i, j})
+			}
+			i = j - 1
+		}
+	}
+
+	return spans
+}
@@ -0,0 +1,43 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestLint(t *testing.T) {
+	tests := map[string][]string{
+		"**/*.go":                   {},
+		"src/**/*_test.go":          {},
+		"\\*\\*.go":                 {},
+		"[*][*].go":                 {},
+		"**":                        {LintOverlyBroad},
+		"**/*":                      {LintOverlyBroad},
+		"src/**":                    {}, // a trailing ** matches files at any depth
+		"**.go":                     {LintRecursiveWildcardSegment},
+		"src/foo**/*.go":            {LintRecursiveWildcardSegment},
+		"[a-":                       {LintInvalid},
+		"**/**/**/**/**/**/**/*.go": {LintHighComplexity},
+	}
+
+	for pat, want := range tests {
+		codes := make([]string, 0)
+		for _, w := range Lint(pat) {
+			codes = append(codes, w.Code)
+		}
+		ExpectThat(t, codes).Is(DeepEqual(want))
+	}
+}
+
+func TestLint_suggestion(t *testing.T) {
+	ExpectThat(t, Lint("**.go")).Is(DeepEqual([]Warning{
+		{
+			Code:       LintRecursiveWildcardSegment,
+			Message:    `** in "**.go" does not form a complete path segment`,
+			Suggestion: `use "**/*.go"`,
+		},
+	}))
+
+	ExpectThat(t, Lint("src/foo**/*.go")[0].Suggestion).Is(Equal(`use "src/foo*/**/*.go"`))
+}
@@ -0,0 +1,43 @@
+package pattern
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewMultiline creates patterns from s which contains one pattern per line.
+// Leading and trailing whitespace is trimmed from each line. Blank lines and
+// comment lines starting with # are skipped. The patterns are returned in the
+// order they appear in s. NewMultiline stops at the first invalid pattern and
+// returns an error containing its line number which wraps ErrBadPattern.
+func NewMultiline(s string) ([]*Pattern, error) {
+	var patterns []*Pattern
+
+	for n, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := New(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		patterns = append(patterns, p)
+	}
+
+	return patterns, nil
+}
+
+// MatchAny reports whether any of patterns matches path. It returns false if
+// patterns is empty.
+func MatchAny(patterns []*Pattern, path string) bool {
+	for _, p := range patterns {
+		if p.Match(path) {
+			return true
+		}
+	}
+
+	return false
+}
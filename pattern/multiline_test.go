@@ -0,0 +1,40 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestNewMultiline(t *testing.T) {
+	patterns, err := NewMultiline(`# Sources
+**/*.go
+
+  # Documentation  
+  docs/*.md  
+`)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(patterns)).Is(Equal(2))
+	ExpectThat(t, patterns[0].GoString()).Is(Equal(MustNew("**/*.go").GoString()))
+	ExpectThat(t, patterns[1].GoString()).Is(Equal(MustNew("docs/*.md").GoString()))
+
+	patterns, err = NewMultiline("\n# only a comment\n\n")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(patterns)).Is(Equal(0))
+}
+
+func TestNewMultiline_invalid(t *testing.T) {
+	_, err := NewMultiline("*.go\r\n\r\n[a\r\n")
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+	ExpectThat(t, err.Error()).Is(StringContaining("line 3"))
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns, err := NewMultiline("*.go\ndocs/*.md")
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, MatchAny(patterns, "main.go")).Is(Equal(true))
+	ExpectThat(t, MatchAny(patterns, "docs/README.md")).Is(Equal(true))
+	ExpectThat(t, MatchAny(patterns, "README.md")).Is(Equal(false))
+	ExpectThat(t, MatchAny(nil, "main.go")).Is(Equal(false))
+}
@@ -0,0 +1,56 @@
+package pattern
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Normalize parses pat and returns it in canonical form. Semantically
+// equivalent patterns are normalized to the same string which makes the
+// result suitable as a key when caching or deduplicating patterns.
+// Normalizing removes escapes from runes without a special meaning (i.e. a\b
+// becomes ab), sorts the runes and ranges of groups, removes duplicate runes
+// and ranges as well as runes already contained in a range of the same group
+// and replaces ranges consisting of a single rune with that rune. Normalize
+// is idempotent. It returns an error if pat is invalid.
+func Normalize(pat string) (string, error) {
+	tokens, err := parse(pat)
+	if err != nil {
+		return "", err
+	}
+
+	for i, t := range tokens {
+		if t.t == tokenTypeGroup {
+			tokens[i].g = normalizeGroup(t.g)
+		}
+	}
+
+	return formatTokens(tokens), nil
+}
+
+// normalizeGroup returns the canonical form of g.
+func normalizeGroup(g runeGroup) runeGroup {
+	n := runeGroup{neg: g.neg}
+
+	for _, rg := range g.ranges {
+		if rg.lo == rg.hi {
+			n.runes = append(n.runes, rg.lo)
+			continue
+		}
+		n.ranges = append(n.ranges, rg)
+	}
+	slices.SortFunc(n.ranges, func(a, b runeRange) int {
+		return cmp.Or(cmp.Compare(a.lo, b.lo), cmp.Compare(a.hi, b.hi))
+	})
+	n.ranges = slices.Compact(n.ranges)
+
+	for _, r := range g.runes {
+		if !slices.ContainsFunc(n.ranges, func(rg runeRange) bool { return rg.match(r) }) {
+			n.runes = append(n.runes, r)
+		}
+	}
+	slices.Sort(n.runes)
+	n.runes = slices.Compact(n.runes)
+
+	return n
+}
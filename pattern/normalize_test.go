@@ -0,0 +1,42 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"**/*.go":       "**/*.go",
+		`a\b`:           "ab",
+		`a\.go`:         "a.go",
+		`\*.go`:         `\*.go`,
+		`a\-b\^c`:       "a-b^c",
+		"[cba].go":      "[abc].go",
+		"[aab].go":      "[ab].go",
+		"[a-cb].go":     "[a-c].go",
+		"[x-zx-za-c]":   "[a-cx-z]",
+		"[^b-ba]":       "[^ab]",
+		`[\]\-]`:        `[\-\]]`,
+		`[\^a]`:         `[\^a]`,
+		`src/[\*?].txt`: "src/[*?].txt",
+	}
+
+	for pat, want := range tests {
+		t.Run(pat, func(t *testing.T) {
+			got, err := Normalize(pat)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, got).Is(Equal(want))
+
+			again, err := Normalize(got)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, again).Is(Equal(got))
+		})
+	}
+}
+
+func TestNormalize_invalid(t *testing.T) {
+	_, err := Normalize("[a-")
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+}
@@ -0,0 +1,100 @@
+package pattern
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+)
+
+// GlobFSParallel works like GlobFSContext but walks the directories found
+// directly under root in parallel using up to concurrency goroutines. The
+// root directory itself is read serially. Values of concurrency less than 1
+// are treated as 1. The returned paths are sorted. If any walk fails, all
+// other walks are canceled and the first error is returned.
+func (pat *Pattern) GlobFSParallel(ctx context.Context, fsys fs.FS, root string, concurrency int) ([]string, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	dir := root
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		results  = make([]string, 0)
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+
+		p := path.Join(root, e.Name())
+		rel := relative(root, p)
+
+		if !e.IsDir() {
+			if pat.Match(rel) {
+				mu.Lock()
+				results = append(results, rel)
+				mu.Unlock()
+			}
+			continue
+		}
+
+		if !pat.CanDescend(rel) {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(start string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var matches []string
+			err := pat.walkFrom(ctx, fsys, root, start, nil, func(p string, _ fs.DirEntry) error {
+				matches = append(matches, p)
+				return nil
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				cancel()
+				return
+			}
+
+			results = append(results, matches...)
+		}(p)
+	}
+
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+
+	sort.Strings(results)
+
+	return results, firstErr
+}
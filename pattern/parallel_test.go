@@ -0,0 +1,104 @@
+package pattern
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_GlobFSParallel(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.EmptyFile("main_test.go"),
+		fsmock.NewDir("internal",
+			fsmock.NewDir("tool",
+				fsmock.EmptyFile("tool.go"),
+				fsmock.EmptyFile("tool_test.go"),
+			),
+			fsmock.NewDir("cli",
+				fsmock.EmptyFile("cli.go"),
+				fsmock.EmptyFile("cli_test.go"),
+			),
+		),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	pat := mustNew(t, "**/*_test.go")
+
+	for _, concurrency := range []int{0, 1, 4} {
+		files, err := pat.GlobFSParallel(context.Background(), fsys, "", concurrency)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, files).Is(DeepEqual([]string{
+			"cmd/main_test.go",
+			"internal/cli/cli_test.go",
+			"internal/tool/tool_test.go",
+			"main_test.go",
+		}))
+	}
+}
+
+func TestPattern_GlobFSParallel_canceled(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := mustNew(t, "**/*_test.go").GlobFSParallel(ctx, fsys, "", 2)
+	ExpectThat(t, err).Is(Error(context.Canceled))
+}
+
+func benchmarkFS() *fsmock.FS {
+	dirs := make([]fsmock.Entry, 0, 50)
+	for i := 0; i < 50; i++ {
+		files := make([]fsmock.Entry, 0, 200)
+		for j := 0; j < 200; j++ {
+			files = append(files, fsmock.EmptyFile(fmt.Sprintf("file_%03d.go", j)))
+		}
+		dirs = append(dirs, fsmock.NewDir(fmt.Sprintf("dir_%02d", i), files...))
+	}
+
+	return fsmock.New(fsmock.NewDir("", dirs...))
+}
+
+func BenchmarkGlobFS_serial(b *testing.B) {
+	fsys := benchmarkFS()
+	p, err := New("**/*.go")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := p.GlobFSContext(context.Background(), fsys, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGlobFS_parallel(b *testing.B) {
+	fsys := benchmarkFS()
+	p, err := New("**/*.go")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := p.GlobFSParallel(context.Background(), fsys, "", 8); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
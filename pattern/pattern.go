@@ -13,13 +13,30 @@
 //	term    -> name
 //	name    -> (char | '*' | '?')+
 //	char    -> <any character except '/', '*' or '?'>
+//
+// A directory wildcard followed by a separator matches zero or more
+// directories. A directory wildcard ending the pattern matches any file at
+// any depth, i.e. src/** matches src/main.go as well as src/cmd/main.go and
+// is equivalent to src/**/*.
+//
+// The parser and matcher are covered by fuzz tests. To run one of them (i.e.
+// FuzzNew, FuzzMatch or FuzzGlobFS), execute
+//
+//	go test -run '^$' -fuzz '^FuzzMatch$' -fuzztime 1m ./pattern
+//
+// from the module's root directory.
 package pattern
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"iter"
+	"path"
+	"slices"
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
@@ -51,21 +68,179 @@ var (
 	ErrBadPattern = errors.New("bad pattern")
 )
 
+// Entry is a single file matched by a Pattern. It carries the path relative
+// to the globbed root as well as the fs.DirEntry reported during the walk.
+type Entry struct {
+	// Path of the file relative to the globbed root
+	Path string
+	fs.DirEntry
+}
+
 // Pattern defines a glob pattern prepared ahead of time which can be used to
 // match filenames. Pattern is safe to use concurrently.
 type Pattern struct {
+	// the pattern's source as passed to New
+	source string
 	tokens []token
+	// Literal runes at the start of tokens and the number of tokens they
+	// span. Paths are checked for the prefix before entering the matcher.
+	prefix       string
+	prefixTokens int
+	// Literal runes at the end of tokens. Paths not ending with suffix are
+	// rejected before entering the matcher.
+	suffix string
+	// maximum number of directories matched by a directory wildcard; zero
+	// means unlimited
+	maxDepth int
+	// maximum complexity; zero means unlimited
+	maxComplexity int
+	// whether groups match using Unicode case folding
+	unicodeFolding bool
+	// whether drive letters are supported and the pattern's drive letter
+	windowsPaths bool
+	drive        string
+	// op and patterns are set for patterns created from combinators such as
+	// Any or All.
+	op       combinatorOp
+	patterns []*Pattern
+}
+
+// Option defines a function that customizes a Pattern. Options are passed
+// to NewWithOptions.
+type Option func(*Pattern)
+
+// WithMaxDepth limits the number of nested directories a single directory
+// wildcard (**) may match to n. A value of zero (the default) means
+// unlimited.
+func WithMaxDepth(n int) Option {
+	return func(p *Pattern) {
+		p.maxDepth = n
+	}
 }
 
 // New creates a new pattern from pat and returns it. It returns an error
 // indicating any invalid pattern.
 func New(pat string) (*Pattern, error) {
-	var tokens []token
+	return NewWithOptions(pat)
+}
+
+// MustNew works like New but panics if pat is invalid. The panic message
+// contains pat as well as the error returned from New. MustNew simplifies
+// safe initialization of global variables holding patterns, i.e.
+//
+//	var goFiles = pattern.MustNew("**/*.go")
+func MustNew(pat string) *Pattern {
+	return MustNewWithOptions(pat)
+}
+
+// MustNewWithOptions works like NewWithOptions but panics if pat is invalid.
+// See MustNew.
+func MustNewWithOptions(pat string, opts ...Option) *Pattern {
+	p, err := NewWithOptions(pat, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("pattern: New(%q): %s", pat, err))
+	}
+	return p
+}
+
+// NewWithOptions creates a new pattern from pat customized with opts and
+// returns it. It returns an error indicating any invalid pattern.
+func NewWithOptions(pat string, opts ...Option) (*Pattern, error) {
+	p := &Pattern{source: pat}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.windowsPaths {
+		p.drive, pat = splitDrive(pat)
+	}
+
+	tokens, err := parse(pat)
+	if err != nil {
+		return nil, err
+	}
+	if p.unicodeFolding {
+		foldGroups(tokens)
+	}
+	p.tokens = tokens
+	p.prefix, p.prefixTokens = literalPrefix(tokens)
+	p.suffix = literalSuffix(tokens)
+
+	if err := p.checkComplexity(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// literalPrefix returns the runes of all leading literal tokens of tokens as
+// a string along with the number of those tokens.
+func literalPrefix(tokens []token) (string, int) {
+	var b strings.Builder
+	n := 0
+	for ; n < len(tokens) && tokens[n].t == tokenTypeLiteral; n++ {
+		b.WriteRune(tokens[n].r)
+	}
+	return b.String(), n
+}
+
+// literalSuffix returns the runes of all trailing literal tokens of tokens as
+// a string. The separator following a directory wildcard is excluded as it
+// is not part of the path if the wildcard matches no directory at all.
+func literalSuffix(tokens []token) string {
+	i := len(tokens)
+	for i > 0 && tokens[i-1].t == tokenTypeLiteral {
+		i--
+	}
+
+	if i > 0 && i < len(tokens) && tokens[i-1].t == tokenTypeAnyDirectories {
+		i++
+	}
 
+	var b strings.Builder
+	for _, t := range tokens[i:] {
+		b.WriteRune(t.r)
+	}
+	return b.String()
+}
+
+// tokenBufs pools the buffers used to collect tokens while parsing.
+var tokenBufs = sync.Pool{
+	New: func() any {
+		buf := make([]token, 0, 32)
+		return &buf
+	},
+}
+
+// parse parses pat into a list of tokens. Tokens are collected in a pooled
+// buffer and copied into a precisely sized slice once parsing succeeded.
+func parse(pat string) ([]token, error) {
+	buf := tokenBufs.Get().(*[]token)
+
+	tokens, err := parseInto((*buf)[:0], pat)
+
+	var result []token
+	if err == nil && len(tokens) > 0 {
+		result = make([]token, len(tokens))
+		copy(result, tokens)
+	}
+
+	// Drop references to rune groups and keep a grown buffer for the next
+	// call.
+	clear(tokens)
+	*buf = tokens[:0]
+	tokenBufs.Put(buf)
+
+	return result, err
+}
+
+// parseInto parses pat and appends the tokens to tokens.
+func parseInto(tokens []token, pat string) ([]token, error) {
 	p := pat
 	for {
 		if len(p) == 0 {
-			return &Pattern{tokens: tokens}, nil
+			return tokens, nil
 		}
 
 		r, l := utf8.DecodeRuneInString(p)
@@ -74,19 +249,19 @@ func New(pat string) (*Pattern, error) {
 		switch r {
 		case Separator:
 			if len(tokens) > 0 && tokens[len(tokens)-1].r == Separator {
-				return nil, fmt.Errorf("%w: unexpected //", ErrBadPattern)
+				return tokens, fmt.Errorf("%w: unexpected //", ErrBadPattern)
 			}
 			t = token{tokenTypeLiteral, Separator, runeGroup{}}
 
 		case SingleWildcard:
 			if len(tokens) > 0 && (tokens[len(tokens)-1].t == tokenTypeAnyRunes || tokens[len(tokens)-1].t == tokenTypeAnyDirectories) {
-				return nil, fmt.Errorf("%w: unexpected ?", ErrBadPattern)
+				return tokens, fmt.Errorf("%w: unexpected ?", ErrBadPattern)
 			}
 			t = token{tokenTypeSingleRune, 0, runeGroup{}}
 
 		case AnyWildcard:
 			if len(tokens) > 0 && (tokens[len(tokens)-1].t == tokenTypeSingleRune || tokens[len(tokens)-1].t == tokenTypeAnyDirectories) {
-				return nil, fmt.Errorf("%w: unexpected ?", ErrBadPattern)
+				return tokens, fmt.Errorf("%w: unexpected ?", ErrBadPattern)
 			}
 
 			t = token{tokenTypeAnyRunes, 0, runeGroup{}}
@@ -95,8 +270,8 @@ func New(pat string) (*Pattern, error) {
 				n, nl := utf8.DecodeRuneInString(p[l:])
 				if n == AnyWildcard {
 					d, _ := utf8.DecodeRuneInString(p[l+nl:])
-					if d != Separator {
-						return nil, fmt.Errorf("%w: unexpected %c after **", ErrBadPattern, d)
+					if len(p[l+nl:]) > 0 && d != Separator {
+						return tokens, fmt.Errorf("%w: unexpected %c after **", ErrBadPattern, d)
 					}
 
 					t.t = tokenTypeAnyDirectories
@@ -106,7 +281,7 @@ func New(pat string) (*Pattern, error) {
 
 		case Backslash:
 			if len(p[l:]) == 0 {
-				return nil, fmt.Errorf("%w: no character given after \\", ErrBadPattern)
+				return tokens, fmt.Errorf("%w: no character given after \\", ErrBadPattern)
 			}
 
 			p = p[l:]
@@ -118,11 +293,11 @@ func New(pat string) (*Pattern, error) {
 			var err error
 			t, l, err = parseGroup(p)
 			if err != nil {
-				return nil, err
+				return tokens, err
 			}
 
 		case GroupEnd:
-			return nil, fmt.Errorf("%w: using ] w/o [", ErrBadPattern)
+			return tokens, fmt.Errorf("%w: using ] w/o [", ErrBadPattern)
 
 		default:
 			t = token{tokenTypeLiteral, r, runeGroup{}}
@@ -136,38 +311,384 @@ func New(pat string) (*Pattern, error) {
 // Match matches a file's path name f to the compiled pattern and returns
 // whether the path matches the pattern or not.
 func (pat *Pattern) Match(f string) bool {
-	return match(f, pat.tokens)
+	return pat.match(f, nil)
+}
+
+// match implements Match. If stats is not nil, the matcher's work is
+// accumulated in stats.
+func (pat *Pattern) match(f string, stats *MatchStats) bool {
+	if pat.op != opNone {
+		return pat.matchCombinator(f, stats)
+	}
+
+	f, ok := pat.stripDrive(f)
+	if !ok {
+		return false
+	}
+
+	// Reject paths not starting with the literal prefix or not ending with
+	// the literal suffix without entering the backtracking matcher.
+	if !strings.HasPrefix(f, pat.prefix) || !strings.HasSuffix(f, pat.suffix) {
+		return false
+	}
+
+	return matchAt(f, len(pat.prefix), pat.tokens[pat.prefixTokens:], pat.maxDepth, 0, stats)
+}
+
+// MatchList matches all of paths against pat and returns a slice of the same
+// length reporting for each path whether it matches.
+func (pat *Pattern) MatchList(paths []string) []bool {
+	res := make([]bool, len(paths))
+	for i, p := range paths {
+		res[i] = pat.Match(p)
+	}
+	return res
+}
+
+// HasRecursiveWildcard reports whether pat contains a directory wildcard
+// (**) and thus may match files at an arbitrary depth.
+func (pat *Pattern) HasRecursiveWildcard() bool {
+	if pat.op != opNone {
+		return pat.hasRecursiveWildcardCombinator()
+	}
+
+	for _, t := range pat.tokens {
+		if t.t == tokenTypeAnyDirectories {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchEntry reports whether the entry d contained in directory dir matches
+// pat. Only files are matched so MatchEntry returns false for a directory
+// without constructing its path. Use MatchEntry when processing the entries
+// returned from fs.ReadDir.
+func (pat *Pattern) MatchEntry(dir string, d fs.DirEntry) bool {
+	if d.IsDir() {
+		return false
+	}
+
+	if dir == "" || dir == "." {
+		return pat.matchEntry(d.Name(), d)
+	}
+
+	return pat.matchEntry(dir+string(Separator)+d.Name(), d)
+}
+
+// matchEntry reports whether the entry d found at p matches pat.
+func (pat *Pattern) matchEntry(p string, d fs.DirEntry) bool {
+	return !d.IsDir() && pat.Match(p)
+}
+
+// CanDescend reports whether pat may match any file contained (directly or
+// indirectly) in directory dir. It is used to skip directories while walking
+// a filesystem.
+func (pat *Pattern) CanDescend(dir string) bool {
+	if pat.op != opNone {
+		return pat.canDescendCombinator(dir)
+	}
+
+	dir, ok := pat.stripDrive(dir)
+	if !ok {
+		return false
+	}
+
+	dir = strings.TrimSuffix(dir, string(Separator))
+	if dir == "" || dir == "." {
+		return true
+	}
+
+	return canDescend(dir+string(Separator), pat.tokens)
 }
 
 // GlobFS applies pat to all files found in fsys under root and returns the
 // matching path names as a string slice. It uses fs.WalkDir internally and all
-// constraints given for that function apply to GlobFS.
-func (pat *Pattern) GlobFS(fsys fs.FS, root string) ([]string, error) {
+// constraints given for that function apply to GlobFS. opts customize the
+// walk; see WithPruner and WithSortedResults. Unless pat contains a directory
+// wildcard (**), GlobFS does not descend deeper than the number of
+// directories given in pat (see GlobFSDepth).
+func (pat *Pattern) GlobFS(fsys fs.FS, root string, opts ...GlobOption) ([]string, error) {
+	return pat.globFSDepth(fsys, root, pat.patternDepth(), opts...)
+}
+
+// GlobFSDepth works like GlobFS but does not descend into directories whose
+// files are more than maxDepth directories below root. Files contained in
+// root are at depth zero, files contained in a direct subdirectory of root
+// at depth one and so on. A negative maxDepth means unlimited.
+func (pat *Pattern) GlobFSDepth(fsys fs.FS, root string, maxDepth int) ([]string, error) {
+	return pat.globFSDepth(fsys, root, maxDepth)
+}
+
+// globFSDepth implements GlobFS and GlobFSDepth.
+func (pat *Pattern) globFSDepth(fsys fs.FS, root string, maxDepth int, opts ...GlobOption) ([]string, error) {
+	var o globOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	prune := o.prune
+	if maxDepth >= 0 {
+		prune = func(dir string, d fs.DirEntry) bool {
+			return strings.Count(dir, string(Separator))+1 <= maxDepth && (o.prune == nil || o.prune(dir, d))
+		}
+	}
+
 	results := make([]string, 0)
-	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+	err := pat.walkFrom(context.Background(), fsys, root, root, prune, func(p string, _ fs.DirEntry) error {
+		results = append(results, p)
+		return nil
+	})
+
+	if o.sorted {
+		slices.Sort(results)
+	}
+
+	return results, err
+}
+
+// GlobFSInfo works like GlobFS but returns a map from each matching path
+// name to the file's info. The info is obtained from the fs.DirEntry reported
+// by fs.WalkDir so no additional call to fs.Stat is issued for fsys
+// implementations providing the info along with directory entries. Files
+// removed while the walk is in progress are omitted.
+func (pat *Pattern) GlobFSInfo(fsys fs.FS, root string) (map[string]fs.FileInfo, error) {
+	results := make(map[string]fs.FileInfo)
+	err := pat.walk(context.Background(), fsys, root, func(p string, d fs.DirEntry) error {
+		info, err := d.Info()
 		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
 			return err
 		}
 
+		results[p] = info
+		return nil
+	})
+
+	return results, err
+}
+
+// GlobAll applies pat to all files found in fsys under each of roots and
+// returns the matching path names. Unlike GlobFS, each path name is prefixed
+// with the root it has been found under, so equal relative paths found under
+// different roots are all contained in the result. A path found under
+// multiple (overlapping) roots is only returned once. If globbing any of the
+// roots fails, GlobAll returns the error along with the path names found so
+// far.
+func (pat *Pattern) GlobAll(fsys fs.FS, roots ...string) ([]string, error) {
+	results := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, root := range roots {
+		for p, err := range pat.Walk(fsys, root) {
+			if err != nil {
+				return results, err
+			}
+
+			p = path.Join(root, p)
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			results = append(results, p)
+		}
+	}
+
+	return results, nil
+}
+
+// Walk returns an iterator yielding the path names of all files found in
+// fsys under root that match pat. Paths are yielded one at a time while the
+// directory walk progresses. If the walk fails, the error is yielded as the
+// second value with an empty path and the iteration stops.
+func (pat *Pattern) Walk(fsys fs.FS, root string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		err := pat.walk(context.Background(), fsys, root, func(p string, _ fs.DirEntry) error {
+			if !yield(p, nil) {
+				return errStopWalk
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopWalk) {
+			yield("", err)
+		}
+	}
+}
+
+// WalkFunc returns an fs.WalkDirFunc to be passed to fs.WalkDir that invokes
+// fn for every file matching pat. Directories that cannot contain any
+// matching file are skipped (see CanDescend). Errors reported by fs.WalkDir
+// are passed to fn along with the path they occurred for, so fn decides
+// whether to continue the walk. Any error returned from fn is returned to
+// fs.WalkDir.
+//
+// Paths are matched as passed to the returned function, so walk from "."
+// or include the root directory in pat:
+//
+//	err := fs.WalkDir(fsys, ".", pat.WalkFunc(func(path string, d fs.DirEntry, err error) error {
+//		// ...
+//	}))
+func (pat *Pattern) WalkFunc(fn func(path string, d fs.DirEntry, err error) error) fs.WalkDirFunc {
+	return func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(p, d, err)
+		}
+
 		if d.IsDir() {
-			// TODO: Optimize with descend into checks
+			if p != "." && !pat.CanDescend(p) {
+				return fs.SkipDir
+			}
 			return nil
 		}
 
-		if root != "." && root != "" {
-			p = strings.Replace(p, root, "", 1)
+		if pat.matchEntry(p, d) {
+			return fn(p, d, nil)
 		}
 
-		if pat.Match(p) {
+		return nil
+	}
+}
+
+// GlobFSContext works like GlobFS but checks ctx before visiting each entry.
+// If ctx is done, the walk is terminated and ctx's error is returned along
+// with the matches found so far.
+func (pat *Pattern) GlobFSContext(ctx context.Context, fsys fs.FS, root string) ([]string, error) {
+	results := make([]string, 0)
+	err := pat.walk(ctx, fsys, root, func(p string, _ fs.DirEntry) error {
+		results = append(results, p)
+		return nil
+	})
+
+	return results, err
+}
+
+// GlobFSFiltered works like GlobFS but additionally invokes filter for every
+// path matching pat. Only paths for which filter returns true are included
+// in the result. filter receives the path relative to root and the entry as
+// reported by fs.WalkDir.
+func (pat *Pattern) GlobFSFiltered(fsys fs.FS, root string, filter func(string, fs.DirEntry) bool) ([]string, error) {
+	results := make([]string, 0)
+	err := pat.walk(context.Background(), fsys, root, func(p string, d fs.DirEntry) error {
+		if filter(p, d) {
 			results = append(results, p)
 		}
+		return nil
+	})
+
+	return results, err
+}
+
+// GlobFSEntries works like GlobFS but returns an Entry for each matching file
+// carrying the fs.DirEntry reported by fs.WalkDir. Use this to access a file's
+// type or info without issuing an additional call to fs.Stat.
+func (pat *Pattern) GlobFSEntries(fsys fs.FS, root string) ([]Entry, error) {
+	return pat.GlobFSEntriesContext(context.Background(), fsys, root)
+}
 
+// GlobFSEntriesContext works like GlobFSEntries but checks ctx before visiting
+// each entry. See GlobFSContext.
+func (pat *Pattern) GlobFSEntriesContext(ctx context.Context, fsys fs.FS, root string) ([]Entry, error) {
+	results := make([]Entry, 0)
+	err := pat.walk(ctx, fsys, root, func(p string, d fs.DirEntry) error {
+		results = append(results, Entry{Path: p, DirEntry: d})
 		return nil
 	})
 
 	return results, err
 }
 
+// GlobChan applies pat to all files found in fsys under root and streams
+// the matching path names through the first returned channel as they are
+// found. The walk runs in its own goroutine. Once the walk finishes or ctx is
+// done, both channels are closed. The error channel receives at most one
+// value which is the error that terminated the walk.
+func (pat *Pattern) GlobChan(ctx context.Context, fsys fs.FS, root string) (<-chan string, <-chan error) {
+	results := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(results)
+
+		err := pat.walk(ctx, fsys, root, func(p string, _ fs.DirEntry) error {
+			select {
+			case results <- p:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return results, errs
+}
+
+// errStopWalk is used to terminate a walk when the consumer of an iterator
+// stops the iteration.
+var errStopWalk = errors.New("stop walk")
+
+// walk walks fsys starting at root and invokes fn for every file that matches
+// pat. The path passed to fn is relative to root. Any error returned from fn
+// terminates the walk. ctx is checked before visiting each entry.
+func (pat *Pattern) walk(ctx context.Context, fsys fs.FS, root string, fn func(p string, d fs.DirEntry) error) error {
+	return pat.walkFrom(ctx, fsys, root, root, nil, fn)
+}
+
+// walkFrom works like walk but starts walking at start which must be root or
+// a directory below root. Paths passed to fn are still relative to root. If
+// prune is not nil, directories for which prune returns false are skipped.
+func (pat *Pattern) walkFrom(ctx context.Context, fsys fs.FS, root, start string, prune ShouldDescend, fn func(p string, d fs.DirEntry) error) error {
+	return fs.WalkDir(fsys, start, func(p string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		p = relative(root, p)
+
+		if d.IsDir() {
+			if p == "" || p == "." {
+				return nil
+			}
+			if !pat.CanDescend(p) || (prune != nil && !prune(p, d)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if pat.matchEntry(p, d) {
+			return fn(p, d)
+		}
+
+		return nil
+	})
+}
+
+// relative returns p relative to root. It returns an empty string for root
+// itself.
+func relative(root, p string) string {
+	if root == "." || root == "" {
+		return p
+	}
+
+	if p == root {
+		return ""
+	}
+
+	return strings.TrimPrefix(p, root+"/")
+}
+
 func parseGroup(p string) (token, int, error) {
 	// re-read the [. No need to assert the rune here as it has been
 	// done in the main parsing loop.
@@ -248,20 +769,246 @@ func parseGroup(p string) (token, int, error) {
 	}
 }
 
-// match is used internally to implement a simple recursive backtracking
-// algorithmn using the token list t to match against file path f.
-func match(f string, t []token) bool {
+// matchState records a wildcard the matcher may resume to match more of the
+// path: the byte offset pos into the path at which the wildcard t[0] has been
+// skipped and the number of directories matched by a directory wildcard so
+// far.
+type matchState struct {
+	pos   int
+	t     []token
+	depth int
+}
+
+// memoThreshold is the number of failed alternatives after which matchAt
+// starts to remember the wildcard states it has visited.
+const memoThreshold = 64
+
+// matchKey identifies a wildcard state visited by matchAt: the byte offset
+// into the path, the index of the wildcard token and the number of
+// directories matched by a directory wildcard.
+type matchKey struct {
+	pos, ti, depth int
+}
+
+// matchMemo records the wildcard states visited by matchAt. A nil matchMemo
+// records nothing.
+type matchMemo map[matchKey]struct{}
+
+// visit records k and reports whether k has been visited before.
+func (m matchMemo) visit(k matchKey) bool {
+	if m == nil {
+		return false
+	}
+
+	if _, ok := m[k]; ok {
+		return true
+	}
+
+	m[k] = struct{}{}
+	return false
+}
+
+// matchAt is used internally to implement a simple backtracking algorithmn
+// using the token list t to match against the file path f starting at byte
+// offset pos. Passing offsets instead of re-slicing f keeps the matching
+// loop free of any string handling other than decoding runes. maxDepth
+// limits the number of directories a directory wildcard may match; zero
+// means unlimited. depth is the number of directories matched by the
+// directory wildcard at t[0] so far. If stats is not nil, the number of
+// tokens evaluated and failed alternatives are counted in stats.
+//
+// Wildcards first match as little as possible. The matcher records each
+// wildcard skipped on an explicit stack. Once the remaining tokens fail to
+// match, the most recently recorded wildcard is resumed consuming one more
+// rune or directory. As the stack holds at most one entry per wildcard,
+// neither deeply nested patterns nor long paths grow the call stack.
+//
+// The outcome of following a wildcard only depends on the wildcard's
+// position in the path and in t. Once matching requires a noticeable number
+// of failed alternatives, the matcher remembers the wildcard states visited
+// and abandons any alternative reaching a state again. This bounds the work
+// for patterns with many directory wildcards to a polynomial in the lengths
+// of the path and the pattern.
+func matchAt(f string, pos int, t []token, maxDepth, depth int, stats *MatchStats) bool {
+	// Most patterns contain only a few wildcards, so a small array avoids
+	// allocating the stack in the common case.
+	var buf [8]matchState
+	pending := buf[:0]
+
+	// The memo is only allocated once matching turns out to be expensive.
+	var memo matchMemo
+	failures := 0
+	n := len(t)
+
 	for {
-		if len(f) == 0 {
+	follow:
+		for {
+			if pos == len(f) {
+				// Wildcards left over match the empty rest of f.
+				for len(t) > 0 && t[0].t == tokenTypeAnyRunes {
+					t = t[1:]
+				}
+
+				if len(t) == 0 {
+					return true
+				}
+
+				break
+			}
+
 			if len(t) == 0 {
-				return true
+				break
 			}
 
-			if len(t) == 1 && t[0].t == tokenTypeAnyRunes {
-				return true
+			r, le := utf8.DecodeRuneInString(f[pos:])
+
+			if stats != nil {
+				stats.TokensEvaluated++
 			}
 
-			return false
+			switch t[0].t {
+			case tokenTypeLiteral:
+				if t[0].r != r {
+					break follow
+				}
+
+			case tokenTypeGroup:
+				if !t[0].g.match(r) {
+					break follow
+				}
+
+			case tokenTypeSingleRune:
+				if r == Separator {
+					break follow
+				}
+
+			case tokenTypeAnyRunes:
+				if r == Separator {
+					t = t[1:]
+					depth = 0
+					continue
+				}
+
+				if memo.visit(matchKey{pos: pos, ti: n - len(t)}) {
+					break follow
+				}
+
+				if len(t) > 1 && t[1].t == tokenTypeLiteral && t[1].r != r {
+					// Skipping the wildcard at this rune fails right away, so
+					// let it consume the rune without recording it.
+					pos += le
+					continue
+				}
+
+				pending = append(pending, matchState{pos: pos, t: t})
+				t = t[1:]
+				depth = 0
+				continue
+
+			case tokenTypeAnyDirectories:
+				if len(t) == 1 {
+					if matchTrailingDirectories(f[pos:], maxDepth) {
+						return true
+					}
+					break follow
+				}
+
+				if memo.visit(matchKey{pos: pos, ti: n - len(t), depth: depth}) {
+					break follow
+				}
+
+				if maxDepth <= 0 || depth < maxDepth {
+					pending = append(pending, matchState{pos: pos, t: t, depth: depth})
+				}
+
+				t = t[2:]
+				depth = 0
+				continue
+			}
+
+			t = t[1:]
+			pos += le
+			depth = 0
+		}
+
+		// The current alternative failed. Resume the most recently skipped
+		// wildcard.
+		for {
+			if len(pending) == 0 {
+				return false
+			}
+
+			stats.backtrack()
+
+			failures++
+			if memo == nil && failures > memoThreshold {
+				memo = make(matchMemo)
+			}
+
+			s := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+
+			_, le := utf8.DecodeRuneInString(f[s.pos:])
+
+			if s.t[0].t == tokenTypeAnyRunes {
+				// Let the wildcard consume one more rune.
+				pos, t, depth = s.pos+le, s.t, 0
+				break
+			}
+
+			// Let the directory wildcard consume one more directory and
+			// skip it afterwards. It may be resumed again unless the
+			// maximum depth has been reached. The depth is only tracked if
+			// it is limited.
+			if next, ok := nextSegment(f, s.pos+le); ok {
+				d := 0
+				if maxDepth > 0 {
+					d = s.depth + 1
+				}
+
+				if memo.visit(matchKey{pos: next, ti: n - len(s.t), depth: d}) {
+					continue
+				}
+
+				pos, t, depth = next, s.t[2:], 0
+				if maxDepth <= 0 || d < maxDepth {
+					pending = append(pending, matchState{pos: next, t: s.t, depth: d})
+				}
+				break
+			}
+		}
+	}
+}
+
+// nextSegment returns the byte offset of the path segment following the
+// separator found at or after byte offset pos in f. It returns false if f
+// contains no such separator.
+func nextSegment(f string, pos int) (int, bool) {
+	i := strings.IndexRune(f[pos:], Separator)
+	if i < 0 {
+		return 0, false
+	}
+	return pos + i + 1, true
+}
+
+// matchTrailingDirectories reports whether f is matched by a directory
+// wildcard ending a pattern. Such a wildcard matches any file at any depth,
+// so f must be a non-empty path not ending with a separator. If maxDepth is
+// positive, f must not contain more than maxDepth directories.
+func matchTrailingDirectories(f string, maxDepth int) bool {
+	if len(f) == 0 || f[len(f)-1] == Separator {
+		return false
+	}
+
+	return maxDepth <= 0 || strings.Count(f, string(Separator)) <= maxDepth
+}
+
+// canDescend is a variant of match that reports whether f is a prefix of
+// any path matched by t. f is a directory path that ends with a separator.
+func canDescend(f string, t []token) bool {
+	for {
+		if len(f) == 0 {
+			return true
 		}
 
 		if len(t) == 0 {
@@ -288,41 +1035,14 @@ func match(f string, t []token) bool {
 
 		case tokenTypeAnyRunes:
 			if r == Separator {
-				return match(f, t[1:])
+				return canDescend(f, t[1:])
 			}
 
-			if match(f[le:], t) {
-				return true
-			}
-
-			if match(f, t[1:]) {
-				return true
-			}
+			return canDescend(f[le:], t) || canDescend(f, t[1:])
 
 		case tokenTypeAnyDirectories:
-			if match(f, t[2:]) {
-				return true
-			}
-
-			var l2 int
-			for {
-				if len(f[le+l2:]) == 0 {
-					return false
-				}
-
-				n, nl := utf8.DecodeRuneInString(f[le+l2:])
-				l2 += nl
-
-				if n == Separator {
-					break
-				}
-			}
-
-			if match(f[le+l2:], t[2:]) {
-				return true
-			}
-
-			return match(f[le+l2:], t)
+			// A directory wildcard matches any number of nested directories.
+			return true
 		}
 
 		t = t[1:]
@@ -366,23 +1086,35 @@ type runeGroup struct {
 	runes []rune
 	// All ranges contained in this group
 	ranges []runeRange
+	// Whether runes are matched using Unicode case folding
+	fold bool
 }
 
 // match matches r with g. It returns true if r is matched.
 func (g runeGroup) match(r rune) bool {
+	if g.contains(r) || (g.fold && g.matchFolded(r)) {
+		return !g.neg
+	}
+
+	return g.neg
+}
+
+// contains reports whether r is one of g's runes or contained in one of g's
+// ranges ignoring whether g is negated.
+func (g runeGroup) contains(r rune) bool {
 	for _, ru := range g.runes {
 		if ru == r {
-			return !g.neg
+			return true
 		}
 	}
 
 	for _, rang := range g.ranges {
 		if rang.match(r) {
-			return !g.neg
+			return true
 		}
 	}
 
-	return g.neg
+	return false
 }
 
 // A closed range of runes consisting of all runes between lo and hi both
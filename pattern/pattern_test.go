@@ -1,8 +1,13 @@
 package pattern
 
 import (
+	"context"
 	"errors"
+	"io/fs"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/halimath/fsmock"
 
@@ -31,6 +36,8 @@ var tests = []test{
 	{"?*.go", "", false, ErrBadPattern},
 	{"**?.go", "", false, ErrBadPattern},
 	{"**f", "", false, ErrBadPattern},
+	{"**/f", "", false, nil},
+	{"src/**f", "", false, ErrBadPattern},
 	{"[a-", "", false, ErrBadPattern},
 	{"[a-\\", "", false, ErrBadPattern},
 	{"[\\", "", false, ErrBadPattern},
@@ -42,6 +49,25 @@ var tests = []test{
 	{"**/m.go", "bar/m.go", true, nil},
 	{"**/m.go", "foo/bar/m.go", true, nil},
 
+	{"src/**/m.go", "src/m.go", true, nil},
+	{"src/**/m.go", "src/foo/m.go", true, nil},
+	{"src/**/m.go", "srcm.go", false, nil},
+	{"src/**/m.go", "lib/foo/m.go", false, nil},
+	{"src/**/m.go", "src/foo/m.go.bak", false, nil},
+	{"src/**", "src/a.go", true, nil},
+	{"src/**", "src/a/b.go", true, nil},
+	{"src/**", "other/a.go", false, nil},
+	{"src/**", "src", false, nil},
+	{"src/**", "src/", false, nil},
+	{"src/**", "srca.go", false, nil},
+	{"**", "a.go", true, nil},
+	{"**", "a/b/c.go", true, nil},
+	{"src/*/**", "src/a.go", false, nil},
+	{"src/*/**", "src/a/b.go", true, nil},
+	{"src/main.go", "src/main.go", true, nil},
+	{"src/main.go", "src/main.g", false, nil},
+	{"src/main.go", "src/main.goo", false, nil},
+
 	{"ab[cde]", "abc", true, nil},
 	{"ab[cde]", "abd", true, nil},
 	{"ab[cde]", "abe", true, nil},
@@ -131,6 +157,30 @@ func TestPattern_Match(t *testing.T) {
 	}
 }
 
+func TestPattern_Match_deep(t *testing.T) {
+	pat := MustNew(strings.Repeat("**/", 20) + "*.go")
+	dirs := strings.Repeat("dir/", 199)
+
+	ExpectThat(t, pat.Match(dirs+"main.go")).Is(Equal(true))
+	ExpectThat(t, pat.Match(dirs+"main.md")).Is(Equal(false))
+	ExpectThat(t, MustNew("**/x/*.go").Match(dirs+"main.go")).Is(Equal(false))
+	ExpectThat(t, MustNew("**/dir/*.go").Match(dirs+"main.go")).Is(Equal(true))
+
+	// Paths passing the literal prefix and suffix checks but not matching
+	// must be rejected without trying every combination of wildcards.
+	start := time.Now()
+	ExpectThat(t, pat.Match(dirs+"x/main.go")).Is(Equal(true))
+	ExpectThat(t, MustNew(strings.Repeat("**/", 20)+"x/*.go").Match(dirs+"main.go")).Is(Equal(false))
+	ExpectThat(t, MustNew(strings.Repeat("**/*/", 20)+"x/*.go").Match(dirs+"main.go")).Is(Equal(false))
+	ExpectThat(t, MustNew(strings.Repeat("*d*/", 20)+"**/x/*.go").Match(dirs+"main.go")).Is(Equal(false))
+
+	limited, err := NewWithOptions(strings.Repeat("**/", 20)+"x/*.go", WithMaxDepth(50))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, limited.Match(dirs+"main.go")).Is(Equal(false))
+
+	ExpectThat(t, time.Since(start) < 5*time.Second).Is(Equal(true))
+}
+
 func TestPattern_GlobFS(t *testing.T) {
 	fsys := fsmock.New(fsmock.NewDir("",
 		fsmock.EmptyFile("go.mod"),
@@ -167,3 +217,591 @@ func TestPattern_GlobFS(t *testing.T) {
 		"internal/cli/cli_test.go",
 	}))
 }
+
+func TestPattern_GlobFS_withPruner(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.NewDir("vendor",
+			fsmock.EmptyFile("lib.go"),
+			fsmock.NewDir("nested",
+				fsmock.EmptyFile("nested.go"),
+			),
+		),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+			fsmock.NewDir("vendor",
+				fsmock.EmptyFile("lib.go"),
+			),
+		),
+	))
+
+	pat, err := New("**/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []string
+	files, err := pat.GlobFS(fsys, "", WithPruner(func(dir string, d fs.DirEntry) bool {
+		visited = append(visited, dir)
+		return d.Name() != "vendor"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, files).Is(DeepEqual([]string{
+		"main.go",
+		"internal/tool.go",
+	}))
+	ExpectThat(t, visited).Is(DeepEqual([]string{
+		"vendor",
+		"internal",
+		"internal/vendor",
+	}))
+}
+
+func TestPattern_GlobFS_withSortedResults(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("z.go"),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+		),
+		fsmock.EmptyFile("a.go"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+		),
+	))
+
+	files, err := MustNew("**/*.go").GlobFS(fsys, "", WithSortedResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, files).Is(DeepEqual([]string{
+		"a.go",
+		"cmd/main.go",
+		"internal/tool.go",
+		"z.go",
+	}))
+}
+
+func TestMustNew(t *testing.T) {
+	pat := MustNew("**/*_test.go")
+	ExpectThat(t, pat.Match("cmd/main_test.go")).Is(Equal(true))
+	ExpectThat(t, pat.Match("cmd/main.go")).Is(Equal(false))
+}
+
+func TestMustNew_invalid(t *testing.T) {
+	defer func() {
+		r := recover()
+		ExpectThat(t, r).Is(Equal(any(`pattern: New("**/[a-"): bad pattern: missing range end`)))
+	}()
+
+	MustNew("**/[a-")
+	t.Fatal("expected MustNew to panic")
+}
+
+func TestPattern_Walk(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+			fsmock.EmptyFile("tool_test.go"),
+		),
+	))
+
+	pat, err := New("**/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make([]string, 0)
+	for p, err := range pat.Walk(fsys, "") {
+		ExpectThat(t, err).Is(NoError())
+		files = append(files, p)
+	}
+
+	ExpectThat(t, files).Is(DeepEqual([]string{
+		"cmd/main_test.go",
+		"internal/tool_test.go",
+	}))
+
+	// Stopping the iteration early
+	files = files[:0]
+	for p := range pat.Walk(fsys, "") {
+		files = append(files, p)
+		break
+	}
+
+	ExpectThat(t, files).Is(DeepEqual([]string{"cmd/main_test.go"}))
+}
+
+func TestPattern_Walk_error(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	pat, err := New("**/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+	for p, err := range pat.Walk(fsys, "missing") {
+		ExpectThat(t, p).Is(Equal(""))
+		errs = append(errs, err)
+	}
+
+	ExpectThat(t, len(errs)).Is(Equal(1))
+	ExpectThat(t, errs[0]).Is(Error(fs.ErrNotExist))
+}
+
+func TestPattern_GlobFSContext_canceled(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	pat, err := New("**/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	files, err := pat.GlobFSContext(ctx, fsys, "")
+	ExpectThat(t, err).Is(Error(context.Canceled))
+	ExpectThat(t, files).Is(DeepEqual([]string{}))
+}
+
+func TestPattern_GlobChan(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+			fsmock.EmptyFile("tool_test.go"),
+		),
+	))
+
+	pat, err := New("**/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, errs := pat.GlobChan(context.Background(), fsys, "")
+
+	files := make([]string, 0)
+	for f := range results {
+		files = append(files, f)
+	}
+
+	ExpectThat(t, <-errs).Is(NoError())
+	ExpectThat(t, files).Is(DeepEqual([]string{
+		"cmd/main_test.go",
+		"internal/tool_test.go",
+	}))
+}
+
+func TestPattern_GlobChan_canceled(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("a_test.go"),
+		fsmock.EmptyFile("b_test.go"),
+		fsmock.EmptyFile("c_test.go"),
+	))
+
+	pat, err := New("*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results, errs := pat.GlobChan(ctx, fsys, "")
+
+	// Receive a single result, then cancel without draining the channel.
+	<-results
+	cancel()
+
+	timeout := time.After(time.Second)
+
+	for results != nil || errs != nil {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				results = nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			ExpectThat(t, err).Is(Error(context.Canceled))
+		case <-timeout:
+			t.Fatal("channels not closed after cancelation")
+		}
+	}
+}
+
+func TestPattern_GlobFSFiltered(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("go.mod", "module example.com/foo"),
+		fsmock.NewDir("bin",
+			&fsmock.File{Name: "tool", Content: []byte("#!/bin/sh"), Mode: 0755, ModTime: time.Now()},
+			&fsmock.File{Name: "data", Content: []byte("data"), Mode: 0644, ModTime: time.Now()},
+		),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+			fsmock.EmptyFile("empty.go"),
+		),
+	))
+
+	pat, err := New("**/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := func(d fs.DirEntry) fs.FileInfo {
+		i, err := d.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+
+	t.Run("size", func(t *testing.T) {
+		files, err := pat.GlobFSFiltered(fsys, "", func(p string, d fs.DirEntry) bool {
+			return info(d).Size() > 0
+		})
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, files).Is(DeepEqual([]string{
+			"go.mod",
+			"bin/tool",
+			"bin/data",
+			"cmd/main.go",
+		}))
+	})
+
+	t.Run("mode", func(t *testing.T) {
+		files, err := pat.GlobFSFiltered(fsys, "", func(p string, d fs.DirEntry) bool {
+			return info(d).Mode()&0111 != 0
+		})
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, files).Is(DeepEqual([]string{
+			"bin/tool",
+		}))
+	})
+}
+
+func TestPattern_GlobFSEntries(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	pat, err := New("**/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := pat.GlobFSEntries(fsys, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, len(entries)).Is(Equal(2))
+
+	ExpectThat(t, entries[0].Path).Is(Equal("cmd/main.go"))
+	ExpectThat(t, entries[0].Name()).Is(Equal("main.go"))
+	ExpectThat(t, entries[0].IsDir()).Is(Equal(false))
+	info, err := entries[0].Info()
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, info.Size()).Is(Equal(int64(len("package main"))))
+
+	ExpectThat(t, entries[1].Path).Is(Equal("cmd/main_test.go"))
+}
+
+func TestPattern_CanDescend(t *testing.T) {
+	tests := []struct {
+		pattern, dir string
+		want         bool
+	}{
+		{"*.go", ".", true},
+		{"*.go", "cmd", false},
+		{"cmd/*.go", "cmd", true},
+		{"cmd/*.go", "cmd/", true},
+		{"cmd/*.go", "internal", false},
+		{"cmd/*.go", "cmd/foo", false},
+		{"c?d/*.go", "cmd", true},
+		{"[a-c]md/*.go", "cmd", true},
+		{"[a-c]md/*.go", "dmd", false},
+		{"*/*.go", "cmd", true},
+		{"*/*.go", "cmd/foo", false},
+		{"a*/b/*.go", "abc/b", true},
+		{"a*/b/*.go", "abc/c", false},
+		{"**/*.go", "cmd/foo/bar", true},
+		{"cmd/**/*.go", "cmd/foo/bar", true},
+		{"cmd/**/*.go", "internal/foo", false},
+		{"cmd/**", "cmd/foo/bar", true},
+		{"cmd/**", "internal", false},
+		{"cmd", "cmd", false},
+	}
+
+	for _, tt := range tests {
+		pat, err := New(tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := pat.CanDescend(tt.dir); got != tt.want {
+			t.Errorf("New(%#q).CanDescend(%#q): wanted %v but got %v", tt.pattern, tt.dir, tt.want, got)
+		}
+	}
+}
+
+func TestPattern_MatchEntry(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("README.md"),
+			fsmock.NewDir("sub.go"),
+		),
+	))
+
+	pat := MustNew("**/*.go")
+
+	got := make(map[string]bool)
+	for _, dir := range []string{".", "cmd"} {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, e := range entries {
+			got[dir+"/"+e.Name()] = pat.MatchEntry(dir, e)
+		}
+	}
+
+	ExpectThat(t, got).Is(DeepEqual(map[string]bool{
+		"./main.go":     true,
+		"./cmd":         false,
+		"cmd/main.go":   true,
+		"cmd/README.md": false,
+		"cmd/sub.go":    false,
+	}))
+}
+
+func TestPattern_HasRecursiveWildcard(t *testing.T) {
+	tests := map[string]bool{
+		"*.go":        false,
+		"cmd/*/*.go":  false,
+		"\\*\\*/*.go": false,
+		"**/*.go":     true,
+		"cmd/**/*.go": true,
+	}
+
+	for pattern, want := range tests {
+		pat, err := New(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ExpectThat(t, pat.HasRecursiveWildcard()).Is(Equal(want))
+	}
+}
+
+func TestPattern_MatchList(t *testing.T) {
+	pat, err := New("**/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, pat.MatchList(nil)).Is(DeepEqual([]bool{}))
+	ExpectThat(t, pat.MatchList([]string{
+		"main.go",
+		"main_test.go",
+		"cmd/main_test.go",
+		"cmd/main.go",
+	})).Is(DeepEqual([]bool{false, true, true, false}))
+}
+
+func TestNewWithOptions_WithMaxDepth(t *testing.T) {
+	tests := []struct {
+		pattern, f string
+		depth      int
+		match      bool
+	}{
+		{"**/*.go", "c.go", 2, true},
+		{"**/*.go", "a/c.go", 2, true},
+		{"**/*.go", "a/b/c.go", 2, true},
+		{"**/*.go", "a/b/c/d.go", 2, false},
+		{"**/*.go", "a/b/c/d.go", 0, true},
+		{"**/*.go", "a/b/c.go", 1, false},
+		{"**/b/**/*.go", "a/b/c/d.go", 1, true},
+		{"**/b/**/*.go", "a/b/c/d/e.go", 1, false},
+		{"src/**", "src/a/b.go", 1, true},
+		{"src/**", "src/a/b/c.go", 1, false},
+	}
+
+	for _, tt := range tests {
+		pat, err := NewWithOptions(tt.pattern, WithMaxDepth(tt.depth))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := pat.Match(tt.f); got != tt.match {
+			t.Errorf("NewWithOptions(%#q, WithMaxDepth(%d)).Match(%#q): wanted %v but got %v", tt.pattern, tt.depth, tt.f, tt.match, got)
+		}
+	}
+}
+
+func TestPattern_GlobAll(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.NewDir("src",
+			fsmock.EmptyFile("main.go"),
+			fsmock.NewDir("util",
+				fsmock.EmptyFile("util.go"),
+			),
+		),
+		fsmock.NewDir("vendor",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("README.md"),
+		),
+	))
+
+	pat, err := New("**/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := pat.GlobAll(fsys, "src", "vendor", "src/util")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, files).Is(DeepEqual([]string{
+		"src/main.go",
+		"src/util/util.go",
+		"vendor/main.go",
+	}))
+}
+
+func TestPattern_GlobAll_error(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.NewDir("src",
+			fsmock.EmptyFile("main.go"),
+		),
+	))
+
+	files, err := MustNew("*.go").GlobAll(fsys, "src", "missing")
+	ExpectThat(t, err).Is(Error(fs.ErrNotExist))
+	ExpectThat(t, files).Is(DeepEqual([]string{"src/main.go"}))
+}
+
+func TestNew_concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for _, tt := range tests {
+				pat, err := New(tt.pattern)
+				if err != nil {
+					continue
+				}
+
+				if pat.Match(tt.f) != tt.match {
+					t.Errorf("New(%#q).Match(%#q): wanted match %v", tt.pattern, tt.f, tt.match)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestPattern_GlobFSInfo(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+	))
+
+	infos, err := MustNew("**/*.go").GlobFSInfo(fsys, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, len(infos)).Is(Equal(2))
+	ExpectThat(t, infos["cmd/main.go"].Name()).Is(Equal("main.go"))
+	ExpectThat(t, infos["cmd/main.go"].Size()).Is(Equal(int64(len("package main"))))
+	ExpectThat(t, infos["cmd/main_test.go"].Size()).Is(Equal(int64(0)))
+}
+
+func TestPattern_WalkFunc(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("go.mod"),
+		fsmock.NewDir("cmd",
+			fsmock.EmptyFile("main.go"),
+			fsmock.EmptyFile("main_test.go"),
+		),
+		fsmock.NewDir("internal",
+			fsmock.EmptyFile("tool.go"),
+			fsmock.EmptyFile("tool_test.go"),
+		),
+		fsmock.NewDir("docs",
+			fsmock.EmptyFile("README.md"),
+		),
+	))
+
+	calls := make(map[string]int)
+	var dirs []string
+
+	err := fs.WalkDir(fsys, ".", MustNew("*/*_test.go").WalkFunc(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		calls[path]++
+		return nil
+	}))
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, calls).Is(DeepEqual(map[string]int{
+		"cmd/main_test.go":      1,
+		"internal/tool_test.go": 1,
+	}))
+	ExpectThat(t, len(dirs)).Is(Equal(0))
+}
+
+func TestPattern_WalkFunc_error(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	var got error
+	err := fs.WalkDir(fsys, "missing", MustNew("**/*.go").WalkFunc(func(path string, d fs.DirEntry, err error) error {
+		got = err
+		return err
+	}))
+
+	ExpectThat(t, err).Is(Error(fs.ErrNotExist))
+	ExpectThat(t, got).Is(Error(fs.ErrNotExist))
+}
@@ -0,0 +1,58 @@
+package pattern
+
+import "sync"
+
+// DefaultPool is the Pool used by Get.
+var DefaultPool = new(Pool)
+
+// Get returns the pattern for pat from DefaultPool. See Pool.Get.
+func Get(pat string) (*Pattern, error) {
+	return DefaultPool.Get(pat)
+}
+
+// Pool caches compiled patterns by their source string. Use a Pool when the
+// same pattern strings are compiled repeatedly, i.e. from configuration read
+// on every request, to parse each string only once. The zero value is ready
+// to use. Pool is safe to use concurrently.
+type Pool struct {
+	entries sync.Map // string -> *poolEntry
+
+	// newPattern compiles a pattern; nil means New. Tests replace it to count
+	// compilations.
+	newPattern func(string) (*Pattern, error)
+}
+
+// poolEntry holds a pattern compiled exactly once.
+type poolEntry struct {
+	once sync.Once
+	pat  *Pattern
+	err  error
+}
+
+// Get returns the pattern compiled from pat. The pattern is compiled on the
+// first call for pat and cached for subsequent calls; concurrent calls for
+// the same string compile it only once. Errors for invalid patterns are
+// cached as well.
+func (p *Pool) Get(pat string) (*Pattern, error) {
+	e, ok := p.entries.Load(pat)
+	if !ok {
+		e, _ = p.entries.LoadOrStore(pat, new(poolEntry))
+	}
+
+	entry := e.(*poolEntry)
+	entry.once.Do(func() {
+		newPattern := p.newPattern
+		if newPattern == nil {
+			newPattern = New
+		}
+		entry.pat, entry.err = newPattern(pat)
+	})
+
+	return entry.pat, entry.err
+}
+
+// Evict removes the cached pattern for pat from p. The next call to Get
+// compiles pat again.
+func (p *Pool) Evict(pat string) {
+	p.entries.Delete(pat)
+}
@@ -0,0 +1,72 @@
+package pattern
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPool_Get(t *testing.T) {
+	var calls atomic.Int32
+	pool := &Pool{
+		newPattern: func(pat string) (*Pattern, error) {
+			calls.Add(1)
+			return New(pat)
+		},
+	}
+
+	var wg sync.WaitGroup
+	pats := make([]*Pattern, 1000)
+
+	for i := range pats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			p, err := pool.Get("**/*.go")
+			if err != nil {
+				t.Error(err)
+			}
+			pats[i] = p
+		}()
+	}
+
+	wg.Wait()
+
+	ExpectThat(t, int(calls.Load())).Is(Equal(1))
+	for _, p := range pats {
+		ExpectThat(t, p == pats[0]).Is(Equal(true))
+	}
+	ExpectThat(t, pats[0].Match("cmd/main.go")).Is(Equal(true))
+}
+
+func TestPool_Evict(t *testing.T) {
+	var pool Pool
+
+	p1, err := pool.Get("*.go")
+	ExpectThat(t, err).Is(NoError())
+
+	pool.Evict("*.go")
+
+	p2, err := pool.Get("*.go")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, p1 != p2).Is(Equal(true))
+}
+
+func TestPool_Get_invalid(t *testing.T) {
+	var pool Pool
+
+	_, err := pool.Get("[a")
+	ExpectThat(t, err).Is(Error(ErrBadPattern))
+}
+
+func TestGet(t *testing.T) {
+	p1, err := Get("**/*_test.go")
+	ExpectThat(t, err).Is(NoError())
+
+	p2, err := Get("**/*_test.go")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, p1 == p2).Is(Equal(true))
+}
@@ -0,0 +1,108 @@
+package pattern
+
+import (
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// realWorldPathCount is the number of paths contained in the corpus used by
+// the real world benchmarks.
+const realWorldPathCount = 10_000
+
+var (
+	realWorldDirs = []string{
+		"cmd", "internal", "pkg", "api", "server", "client", "testdata", "docs",
+		"vendor", "github.com", "golang.org", "x", "v2", "handler", "model",
+		"über", "データ", "café",
+	}
+
+	realWorldNames = []string{
+		"main", "server", "client", "handler", "model", "util", "config",
+		"types", "doc", "errors", "größe", "ファイル", "naïve",
+	}
+
+	realWorldSuffixes = []string{
+		".go", ".go", ".go", "_test.go", "_test.go", ".pb.go", "_gen.go",
+		"_string.go", ".md", ".yaml", ".json", ".mod", ".sum",
+	}
+)
+
+// realWorldPaths returns a corpus of paths resembling the files contained in
+// a Go project. The corpus is generated once using a fixed seed so all
+// benchmarks run against the same paths.
+var realWorldPaths = sync.OnceValue(func() []string {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	paths := make([]string, realWorldPathCount)
+	for i := range paths {
+		var b strings.Builder
+
+		for depth := r.IntN(7); depth > 0; depth-- {
+			b.WriteString(realWorldDirs[r.IntN(len(realWorldDirs))])
+			b.WriteRune(Separator)
+		}
+
+		if r.IntN(20) == 0 {
+			b.WriteString("zz_generated.deepcopy.go")
+		} else {
+			b.WriteString(realWorldNames[r.IntN(len(realWorldNames))])
+			b.WriteString(realWorldSuffixes[r.IntN(len(realWorldSuffixes))])
+		}
+
+		paths[i] = b.String()
+	}
+
+	return paths
+})
+
+// benchmarkRealWorld matches pat against the paths of the real world corpus.
+// Each operation matches a single path.
+func benchmarkRealWorld(b *testing.B, pat string) {
+	p, err := New(pat)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	paths := realWorldPaths()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.Match(paths[i%len(paths)])
+	}
+}
+
+func BenchmarkMatch_realWorld(b *testing.B) {
+	patterns := []struct{ name, pat string }{
+		{"sources", "**/*.go"},
+		{"tests", "**/*_test.go"},
+		{"generated", "**/zz_generated.*.go"},
+		{"prefixed", "internal/**/*.go"},
+		{"groups", "**/[a-m]*_[^t]*.go"},
+		{"unicode", "**/über/**/*.go"},
+		{"nested", "**/testdata/**/*.json"},
+		{"single rune", "**/?????.go"},
+	}
+
+	for _, p := range patterns {
+		b.Run(p.name, func(b *testing.B) {
+			benchmarkRealWorld(b, p.pat)
+		})
+	}
+}
+
+// BenchmarkMatch_realWorld_recursive quantifies the backtracking overhead of
+// a directory wildcard compared to a pattern matching a single directory
+// level.
+func BenchmarkMatch_realWorld_recursive(b *testing.B) {
+	b.Run("**", func(b *testing.B) {
+		benchmarkRealWorld(b, "**/*.go")
+	})
+
+	b.Run("*", func(b *testing.B) {
+		benchmarkRealWorld(b, "*.go")
+	})
+}
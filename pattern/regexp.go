@@ -0,0 +1,128 @@
+package pattern
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ToRegexp converts pat into an equivalent, anchored regular expression. The
+// regular expression matches the same paths as pat's Match method which
+// makes it usable with tools that only accept a *regexp.Regexp. Patterns
+// created using Any are converted into an alternation of their patterns'
+// expressions. Patterns created using All or Not cannot be expressed as a
+// regular expression; ToRegexp returns nil for them.
+func (pat *Pattern) ToRegexp() *regexp.Regexp {
+	var b strings.Builder
+
+	b.WriteRune('^')
+	if !pat.writeRegexp(&b) {
+		return nil
+	}
+	b.WriteRune('$')
+
+	return regexp.MustCompile(b.String())
+}
+
+// writeRegexp writes the regular expression for pat to b. It returns false
+// if pat cannot be converted.
+func (pat *Pattern) writeRegexp(b *strings.Builder) bool {
+	switch pat.op {
+	case opAll, opNot:
+		return false
+
+	case opAny:
+		if len(pat.patterns) == 0 {
+			// An empty class never matches.
+			b.WriteString(`[^\x00-\x{10FFFF}]`)
+			return true
+		}
+
+		b.WriteString("(?:")
+		for i, p := range pat.patterns {
+			if i > 0 {
+				b.WriteRune('|')
+			}
+			if !p.writeRegexp(b) {
+				return false
+			}
+		}
+		b.WriteRune(')')
+		return true
+	}
+
+	if pat.windowsPaths && pat.drive != "" {
+		b.WriteString("(?i:")
+		b.WriteString(regexp.QuoteMeta(pat.drive))
+		b.WriteRune(')')
+	}
+
+	for i := 0; i < len(pat.tokens); i++ {
+		t := pat.tokens[i]
+
+		switch t.t {
+		case tokenTypeLiteral:
+			b.WriteString(regexp.QuoteMeta(string(t.r)))
+
+		case tokenTypeSingleRune:
+			b.WriteString("[^/]")
+
+		case tokenTypeAnyRunes:
+			b.WriteString("[^/]*")
+
+		case tokenTypeAnyDirectories:
+			// A directory wildcard ending the pattern matches any file at
+			// any depth.
+			if i == len(pat.tokens)-1 {
+				if pat.maxDepth > 0 {
+					b.WriteString("(?:[^/]*/){0," + strconv.Itoa(pat.maxDepth) + "}[^/]+")
+				} else {
+					b.WriteString("(?:[^/]*/)*[^/]+")
+				}
+				continue
+			}
+
+			// A directory wildcard along with the following separator matches
+			// any number of directories including none.
+			if pat.maxDepth > 0 {
+				b.WriteString("(?:[^/]*/){0," + strconv.Itoa(pat.maxDepth) + "}")
+			} else {
+				b.WriteString("(?:.*/)?")
+			}
+			i++
+
+		case tokenTypeGroup:
+			if t.g.fold {
+				b.WriteString("(?i:")
+			}
+			b.WriteRune('[')
+			if t.g.neg {
+				b.WriteRune('^')
+			}
+			for _, r := range t.g.runes {
+				writeClassRune(b, r)
+			}
+			for _, rg := range t.g.ranges {
+				writeClassRune(b, rg.lo)
+				b.WriteRune('-')
+				writeClassRune(b, rg.hi)
+			}
+			b.WriteRune(']')
+			if t.g.fold {
+				b.WriteRune(')')
+			}
+		}
+	}
+
+	return true
+}
+
+// writeClassRune writes r as part of a character class escaping it if
+// necessary.
+func writeClassRune(b *strings.Builder, r rune) {
+	switch r {
+	case '\\', '[', ']', '^', '-':
+		b.WriteRune('\\')
+	}
+	b.WriteRune(r)
+}
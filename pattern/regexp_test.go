@@ -0,0 +1,59 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_ToRegexp(t *testing.T) {
+	for _, tt := range tests {
+		if tt.err != nil {
+			continue
+		}
+
+		pat := MustNew(tt.pattern)
+		re := pat.ToRegexp()
+
+		if got := re.MatchString(tt.f); got != pat.Match(tt.f) {
+			t.Errorf("New(%#q).ToRegexp() = %s: MatchString(%#q) = %v but Match returns %v", tt.pattern, re, tt.f, got, pat.Match(tt.f))
+		}
+	}
+}
+
+func TestPattern_ToRegexp_expressions(t *testing.T) {
+	tests := map[string]string{
+		"**/*.go":    `^(?:.*/)?[^/]*\.go$`,
+		"a?b":        `^a[^/]b$`,
+		`a\*b`:       `^a\*b$`,
+		"[^a-zα]x":   `^[^αa-z]x$`,
+		`[\]\-x]`:    `^[\]\-x]$`,
+		"src/[a-c]*": `^src/[a-c][^/]*$`,
+		"src/**":     `^src/(?:[^/]*/)*[^/]+$`,
+	}
+
+	for pat, want := range tests {
+		ExpectThat(t, MustNew(pat).ToRegexp().String()).Is(Equal(want))
+	}
+}
+
+func TestPattern_ToRegexp_maxDepth(t *testing.T) {
+	for _, p := range []string{"**/*.go", "**"} {
+		pat := MustNewWithOptions(p, WithMaxDepth(1))
+		re := pat.ToRegexp()
+
+		for _, f := range []string{"a.go", "x/a.go", "x/y/a.go"} {
+			ExpectThat(t, re.MatchString(f)).Is(Equal(pat.Match(f)))
+		}
+	}
+}
+
+func TestPattern_ToRegexp_combinator(t *testing.T) {
+	re := Any(MustNew("*.go"), MustNew("**/*.md")).ToRegexp()
+
+	ExpectThat(t, re.MatchString("main.go")).Is(Equal(true))
+	ExpectThat(t, re.MatchString("docs/README.md")).Is(Equal(true))
+	ExpectThat(t, re.MatchString("cmd/main.go")).Is(Equal(false))
+
+	ExpectThat(t, All(MustNew("*.go"), MustNew("main*")).ToRegexp() == nil).Is(Equal(true))
+}
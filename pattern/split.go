@@ -0,0 +1,100 @@
+package pattern
+
+import (
+	"strings"
+)
+
+// SplitAtFirstWildcard splits pat into a static prefix containing only
+// literal directory names and a dynamic suffix containing the rest of the
+// pattern. The split happens at the last separator preceding the first
+// wildcard or group; if pat contains no wildcard at all, the split happens at
+// the last separator so that the file name is returned as the suffix.
+//
+// The prefix is a plain path name (with any escape sequences resolved) that
+// can be used as the root when globbing or as the directory passed to
+// fs.Sub. The suffix is a valid pattern relative to that root. Given
+//
+//	src/cmd/**/*.go
+//
+// SplitAtFirstWildcard returns "src/cmd" and "**/*.go". The prefix is empty if
+// pat starts with a wildcard. For patterns created using Any or All both
+// strings are empty.
+func (pat *Pattern) SplitAtFirstWildcard() (staticPrefix, dynamicSuffix string) {
+	if pat.op != opNone {
+		return "", ""
+	}
+
+	split := -1
+	for i, t := range pat.tokens {
+		if t.t != tokenTypeLiteral {
+			break
+		}
+		if t.r == Separator {
+			split = i
+		}
+	}
+
+	if split < 0 {
+		return "", formatTokens(pat.tokens)
+	}
+
+	var b strings.Builder
+	for _, t := range pat.tokens[:split] {
+		b.WriteRune(t.r)
+	}
+
+	return b.String(), formatTokens(pat.tokens[split+1:])
+}
+
+// formatTokens formats tokens in pattern syntax escaping any literal rune
+// with a special meaning.
+func formatTokens(tokens []token) string {
+	var b strings.Builder
+
+	for _, t := range tokens {
+		switch t.t {
+		case tokenTypeLiteral:
+			writeEscaped(&b, t.r, false)
+		case tokenTypeSingleRune:
+			b.WriteRune(SingleWildcard)
+		case tokenTypeAnyRunes:
+			b.WriteRune(AnyWildcard)
+		case tokenTypeAnyDirectories:
+			b.WriteRune(AnyWildcard)
+			b.WriteRune(AnyWildcard)
+		case tokenTypeGroup:
+			b.WriteRune(GroupStart)
+			if t.g.neg {
+				b.WriteRune(GroupNegate)
+			}
+			for _, r := range t.g.runes {
+				writeEscaped(&b, r, true)
+			}
+			for _, rg := range t.g.ranges {
+				writeEscaped(&b, rg.lo, true)
+				b.WriteRune(Range)
+				writeEscaped(&b, rg.hi, true)
+			}
+			b.WriteRune(GroupEnd)
+		}
+	}
+
+	return b.String()
+}
+
+// writeEscaped writes r to b preceded by a backslash if r has a special
+// meaning in patterns. inGroup defines whether r is written as part of a
+// group which changes the set of runes with a special meaning.
+func writeEscaped(b *strings.Builder, r rune, inGroup bool) {
+	var special bool
+	if inGroup {
+		special = r == Backslash || r == GroupEnd || r == GroupNegate || r == Range
+	} else {
+		special = r == Backslash || r == GroupStart || r == GroupEnd || r == SingleWildcard || r == AnyWildcard
+	}
+
+	if special {
+		b.WriteRune(Backslash)
+	}
+	b.WriteRune(r)
+}
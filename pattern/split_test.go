@@ -0,0 +1,41 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_SplitAtFirstWildcard(t *testing.T) {
+	tests := map[string][2]string{
+		"src/cmd/**/*.go":     {"src/cmd", "**/*.go"},
+		"*.go":                {"", "*.go"},
+		"foo/bar/baz.go":      {"foo/bar", "baz.go"},
+		"baz.go":              {"", "baz.go"},
+		"src/m?in.go":         {"src", "m?in.go"},
+		"src/[a-c]/*.go":      {"src", "[a-c]/*.go"},
+		"src/cmd*/main.go":    {"src", "cmd*/main.go"},
+		`a\*b/c/[^x\-]*.go`:   {"a*b/c", `[^x\-]*.go`},
+		`docs/\[draft\]/*.md`: {"docs/[draft]", "*.md"},
+	}
+
+	for pat, want := range tests {
+		t.Run(pat, func(t *testing.T) {
+			prefix, suffix := MustNew(pat).SplitAtFirstWildcard()
+
+			ExpectThat(t, prefix).Is(Equal(want[0]))
+			ExpectThat(t, suffix).Is(Equal(want[1]))
+
+			p, err := New(suffix)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, p.Equal(MustNew(want[1]))).Is(Equal(true))
+		})
+	}
+}
+
+func TestPattern_SplitAtFirstWildcard_combinator(t *testing.T) {
+	prefix, suffix := Any(MustNew("*.go"), MustNew("*.md")).SplitAtFirstWildcard()
+
+	ExpectThat(t, prefix).Is(Equal(""))
+	ExpectThat(t, suffix).Is(Equal(""))
+}
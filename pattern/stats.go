@@ -0,0 +1,37 @@
+package pattern
+
+import "time"
+
+// MatchStats reports the work done by the matcher to match a single path. See
+// MatchWithStats.
+type MatchStats struct {
+	// The number of tokens compared against a rune of the path
+	TokensEvaluated int
+	// The number of times the matcher abandoned an alternative for a
+	// wildcard and had to try another one
+	BacktrackCount int
+	// The time spent matching in nanoseconds
+	ElapsedNs int64
+}
+
+// MatchWithStats works like Match but additionally reports statistics about
+// the work done by the matcher. Use it to find patterns or paths that are
+// expensive to match. Paths rejected based on the pattern's literal prefix or
+// suffix report no tokens evaluated. Collecting the statistics slows down
+// matching, so use Match for everything but profiling.
+func (pat *Pattern) MatchWithStats(f string) (bool, MatchStats) {
+	var stats MatchStats
+
+	start := time.Now()
+	matched := pat.match(f, &stats)
+	stats.ElapsedNs = time.Since(start).Nanoseconds()
+
+	return matched, stats
+}
+
+// backtrack counts a failed alternative if s is not nil.
+func (s *MatchStats) backtrack() {
+	if s != nil {
+		s.BacktrackCount++
+	}
+}
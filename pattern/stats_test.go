@@ -0,0 +1,32 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_MatchWithStats(t *testing.T) {
+	const path = "src/a/b/c/d/e/f/main_test.go"
+
+	matched, literal := MustNew(path).MatchWithStats(path)
+	ExpectThat(t, matched).Is(Equal(true))
+	ExpectThat(t, literal.BacktrackCount).Is(Equal(0))
+
+	matched, recursive := MustNew("**/*/**/*_test.go").MatchWithStats(path)
+	ExpectThat(t, matched).Is(Equal(true))
+	ExpectThat(t, recursive.BacktrackCount > literal.BacktrackCount).Is(Equal(true))
+	ExpectThat(t, recursive.TokensEvaluated > 0).Is(Equal(true))
+	ExpectThat(t, recursive.ElapsedNs >= 0).Is(Equal(true))
+
+	matched, _ = MustNew("**/*.md").MatchWithStats(path)
+	ExpectThat(t, matched).Is(Equal(false))
+}
+
+func TestPattern_MatchWithStats_combinator(t *testing.T) {
+	pat := Any(MustNew("*.md"), MustNew("**/*.go"))
+
+	matched, stats := pat.MatchWithStats("cmd/main.go")
+	ExpectThat(t, matched).Is(Equal(true))
+	ExpectThat(t, stats.TokensEvaluated > 0).Is(Equal(true))
+}
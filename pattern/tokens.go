@@ -0,0 +1,92 @@
+package pattern
+
+import "fmt"
+
+// TokenType enumerates the different types of tokens a pattern is parsed
+// into.
+type TokenType int
+
+const (
+	// A literal rune
+	TokenLiteral TokenType = TokenType(tokenTypeLiteral)
+	// Any single non-separator rune (?)
+	TokenSingleRune TokenType = TokenType(tokenTypeSingleRune)
+	// Any number of non-separator runes including zero (*)
+	TokenAnyRunes TokenType = TokenType(tokenTypeAnyRunes)
+	// Any number of directories (**)
+	TokenAnyDirectories TokenType = TokenType(tokenTypeAnyDirectories)
+	// A group of runes and/or rune ranges ([...])
+	TokenGroup TokenType = TokenType(tokenTypeGroup)
+)
+
+// String returns a string representation of t.
+func (t TokenType) String() string {
+	switch t {
+	case TokenLiteral:
+		return "literal"
+	case TokenSingleRune:
+		return "single rune"
+	case TokenAnyRunes:
+		return "any runes"
+	case TokenAnyDirectories:
+		return "any directories"
+	case TokenGroup:
+		return "group"
+	default:
+		return fmt.Sprintf("TokenType(%d)", int(t))
+	}
+}
+
+// Token is a readonly view of a single token of a parsed pattern.
+type Token struct {
+	// The token's type
+	Type TokenType
+	// The rune to match for TokenLiteral; zero otherwise
+	Rune rune
+	// The enumerated runes of a TokenGroup
+	Group []rune
+	// The closed rune ranges of a TokenGroup as pairs of low and high
+	Ranges [][2]rune
+	// Whether a TokenGroup is negated
+	Negated bool
+	// Whether a TokenGroup matches runes using Unicode case folding (see
+	// WithUnicodeFolding)
+	Folded bool
+}
+
+// Tokens returns the tokens pat has been parsed into. The returned slice is a
+// copy so modifying it does not affect pat. Patterns created using Any, All
+// or Not have no tokens on their own and return nil.
+func (pat *Pattern) Tokens() []Token {
+	if pat.op != opNone {
+		return nil
+	}
+
+	tokens := make([]Token, len(pat.tokens))
+	for i, t := range pat.tokens {
+		tokens[i] = Token{
+			Type: TokenType(t.t),
+			Rune: t.r,
+		}
+
+		if t.t != tokenTypeGroup {
+			continue
+		}
+
+		tokens[i].Negated = t.g.neg
+		tokens[i].Folded = t.g.fold
+
+		if len(t.g.runes) > 0 {
+			tokens[i].Group = append([]rune(nil), t.g.runes...)
+		}
+
+		if len(t.g.ranges) > 0 {
+			tokens[i].Ranges = make([][2]rune, len(t.g.ranges))
+			for j, r := range t.g.ranges {
+				tokens[i].Ranges[j] = [2]rune{r.lo, r.hi}
+			}
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,53 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPattern_Tokens(t *testing.T) {
+	tests := map[string][]Token{
+		"a?": {
+			{Type: TokenLiteral, Rune: 'a'},
+			{Type: TokenSingleRune},
+		},
+		"**/*": {
+			{Type: TokenAnyDirectories},
+			{Type: TokenLiteral, Rune: '/'},
+			{Type: TokenAnyRunes},
+		},
+		"[^ab0-9]": {
+			{Type: TokenGroup, Group: []rune{'a', 'b'}, Ranges: [][2]rune{{'0', '9'}}, Negated: true},
+		},
+	}
+
+	for pat, want := range tests {
+		ExpectThat(t, MustNew(pat).Tokens()).Is(DeepEqual(want))
+	}
+}
+
+func TestPattern_Tokens_folded(t *testing.T) {
+	p, err := NewWithOptions("[a-c]", WithUnicodeFolding())
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, p.Tokens()).Is(DeepEqual([]Token{
+		{Type: TokenGroup, Ranges: [][2]rune{{'a', 'c'}}, Folded: true},
+	}))
+}
+
+func TestPattern_Tokens_copy(t *testing.T) {
+	p := MustNew("[ab]")
+	p.Tokens()[0].Group[0] = 'x'
+
+	ExpectThat(t, p.Match("a")).Is(Equal(true))
+	ExpectThat(t, p.Match("x")).Is(Equal(false))
+}
+
+func TestPattern_Tokens_combined(t *testing.T) {
+	ExpectThat(t, Any(MustNew("*.go"), MustNew("*.md")).Tokens() == nil).Is(Equal(true))
+}
+
+func TestTokenType_String(t *testing.T) {
+	ExpectThat(t, TokenAnyDirectories.String()).Is(Equal("any directories"))
+	ExpectThat(t, TokenType(99).String()).Is(Equal("TokenType(99)"))
+}
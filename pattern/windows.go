@@ -0,0 +1,45 @@
+package pattern
+
+import (
+	"runtime"
+	"strings"
+)
+
+// isWindows reports whether the package runs on Windows. It is a variable so
+// that tests can exercise Windows specific behavior on any platform.
+var isWindows = runtime.GOOS == "windows"
+
+// WithWindowsPaths enables support for Windows drive letters. A drive letter
+// prefix such as C: is accepted at the start of the pattern as well as at the
+// start of paths being matched. The prefix is stripped from both before
+// matching; a path only matches if it uses the same drive as the pattern
+// (compared case-insensitively) or if neither carries a drive letter. Thus,
+// C:/Users/**/*.go matches C:/Users/me/main.go but neither D:/Users/me/main.go
+// nor /Users/me/main.go.
+//
+// On systems other than Windows the option is a no-op.
+func WithWindowsPaths() Option {
+	return func(p *Pattern) {
+		p.windowsPaths = isWindows
+	}
+}
+
+// splitDrive splits a leading drive letter (i.e. C:) from p. It returns an
+// empty drive if p does not start with a drive letter.
+func splitDrive(p string) (drive, rest string) {
+	if len(p) >= 2 && p[1] == ':' && ('a' <= p[0] && p[0] <= 'z' || 'A' <= p[0] && p[0] <= 'Z') {
+		return p[:2], p[2:]
+	}
+	return "", p
+}
+
+// stripDrive removes the drive letter from p if pat uses Windows paths. It
+// returns false if p's drive does not match pat's drive.
+func (pat *Pattern) stripDrive(p string) (string, bool) {
+	if !pat.windowsPaths {
+		return p, true
+	}
+
+	drive, rest := splitDrive(p)
+	return rest, strings.EqualFold(drive, pat.drive)
+}
@@ -0,0 +1,36 @@
+package pattern
+
+import (
+	"testing"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWithWindowsPaths(t *testing.T) {
+	defer func(w bool) { isWindows = w }(isWindows)
+	isWindows = true
+
+	pat := MustNewWithOptions("C:/**/*.go", WithWindowsPaths())
+
+	ExpectThat(t, pat.Match("C:/foo/bar.go")).Is(Equal(true))
+	ExpectThat(t, pat.Match("c:/foo/bar.go")).Is(Equal(true))
+	ExpectThat(t, pat.Match("D:/foo/bar.go")).Is(Equal(false))
+	ExpectThat(t, pat.Match("/foo/bar.go")).Is(Equal(false))
+	ExpectThat(t, pat.CanDescend("C:/foo")).Is(Equal(true))
+	ExpectThat(t, pat.CanDescend("D:/foo")).Is(Equal(false))
+
+	pat = MustNewWithOptions("**/*.go", WithWindowsPaths())
+
+	ExpectThat(t, pat.Match("foo/bar.go")).Is(Equal(true))
+	ExpectThat(t, pat.Match("C:foo/bar.go")).Is(Equal(false))
+}
+
+func TestWithWindowsPaths_noop(t *testing.T) {
+	defer func(w bool) { isWindows = w }(isWindows)
+	isWindows = false
+
+	pat := MustNewWithOptions("C:/**/*.go", WithWindowsPaths())
+
+	ExpectThat(t, pat.Match("C:/foo/bar.go")).Is(Equal(true))
+	ExpectThat(t, pat.Match("D:/foo/bar.go")).Is(Equal(false))
+}
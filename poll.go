@@ -0,0 +1,61 @@
+package globwatch
+
+// NextPoll returns a channel that is closed once the next poll cycle started
+// after NextPoll has been called completes successfully. Failed poll cycles
+// are ignored. The channel is also closed when w is closed.
+func (w *Watcher) NextPoll() <-chan struct{} {
+	c := make(chan struct{})
+
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+
+	if w.pollDone {
+		close(c)
+		return c
+	}
+
+	w.pollWaiters = append(w.pollWaiters, c)
+
+	return c
+}
+
+// beginPoll marks the start of a poll cycle and returns the channels to be
+// notified when the cycle completes.
+func (w *Watcher) beginPoll() []chan struct{} {
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+
+	waiters := w.pollWaiters
+	w.pollWaiters = nil
+
+	return waiters
+}
+
+// endPoll marks the end of a poll cycle. If the cycle succeeded, all waiters
+// are notified. Otherwise, they are kept waiting for the next cycle.
+func (w *Watcher) endPoll(waiters []chan struct{}, success bool) {
+	if success {
+		for _, c := range waiters {
+			close(c)
+		}
+		return
+	}
+
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+
+	w.pollWaiters = append(waiters, w.pollWaiters...)
+}
+
+// closePollWaiters notifies all waiters and makes any further calls to
+// NextPoll return a closed channel.
+func (w *Watcher) closePollWaiters() {
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+
+	for _, c := range w.pollWaiters {
+		close(c)
+	}
+	w.pollWaiters = nil
+	w.pollDone = true
+}
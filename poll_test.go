@@ -0,0 +1,50 @@
+package globwatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_NextPoll(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+
+	watcher, err := New(fsys, "*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isClosed := func(c <-chan struct{}) bool {
+		select {
+		case <-c:
+			return true
+		default:
+			return false
+		}
+	}
+
+	polled := watcher.NextPoll()
+	ExpectThat(t, isClosed(polled)).Is(Equal(false))
+
+	// A failing poll does not notify the waiter.
+	watcher.fsys = failingFS{}
+	watcher.detectChanges(context.Background())
+	<-watcher.errors
+	ExpectThat(t, isClosed(polled)).Is(Equal(false))
+
+	watcher.fsys = fsys
+	watcher.detectChanges(context.Background())
+	<-watcher.c
+	ExpectThat(t, isClosed(polled)).Is(Equal(true))
+
+	polled = watcher.NextPoll()
+	watcher.closePollWaiters()
+	ExpectThat(t, isClosed(polled)).Is(Equal(true))
+	ExpectThat(t, isClosed(watcher.NextPoll())).Is(Equal(true))
+}
@@ -0,0 +1,274 @@
+package globwatch
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// Pool manages multiple Watchers and merges their events and errors into a
+// single pair of channels. Each Watcher watches its own filesystem using its
+// own pattern. Events carry paths relative to the root of the filesystem
+// they originate from; use distinct roots if events need to be attributed
+// to a filesystem.
+//
+// As with a single Watcher, make sure to consume both C and Errors or change
+// detection will block.
+type Pool struct {
+	interval time.Duration
+	opts     []Option
+
+	mu       sync.Mutex
+	watchers []*poolWatcher
+	running  bool
+	closed   bool
+
+	c      chan Event
+	errors chan error
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// poolWatcher associates a Watcher with the filesystem it has been added
+// for.
+type poolWatcher struct {
+	fsys fs.FS
+	pat  string
+	w    *Watcher
+	// Closed to stop forwarding w's events and errors when w is removed
+	stop chan struct{}
+}
+
+// NewPool creates a new, empty Pool. Watchers added to the pool use interval
+// and opts.
+func NewPool(interval time.Duration, opts ...Option) *Pool {
+	return &Pool{
+		interval: interval,
+		opts:     opts,
+		c:        make(chan Event, 10),
+		errors:   make(chan error, 10),
+		done:     make(chan struct{}),
+	}
+}
+
+// C returns a channel used to receive events from all watchers.
+func (p *Pool) C() <-chan Event {
+	return p.c
+}
+
+// Errors returns a channel used to receive errors from all watchers.
+func (p *Pool) Errors() <-chan error {
+	return p.errors
+}
+
+// Add adds a Watcher for fsys using pat. If p is running, the Watcher is
+// started immediately. fsys is used to identify the Watcher when calling
+// Remove so its dynamic type must be comparable. Add returns ErrDuplicate if
+// a Watcher for fsys using an equal pattern has already been added.
+func (p *Pool) Add(fsys fs.FS, pat string) error {
+	w, err := New(fsys, pat, p.interval, p.opts...)
+	if err != nil {
+		return err
+	}
+
+	pw := &poolWatcher{fsys: fsys, pat: pat, w: w}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	for _, o := range p.watchers {
+		if o.fsys == fsys && o.w.currentPattern().Equal(w.currentPattern()) {
+			return ErrDuplicate
+		}
+	}
+
+	if p.running {
+		if err := p.start(pw); err != nil {
+			return err
+		}
+	}
+
+	p.watchers = append(p.watchers, pw)
+
+	return nil
+}
+
+// Remove closes and removes all Watchers added for fsys regardless of their
+// patterns. Events and errors of these Watchers not yet received from C or
+// Errors are discarded. It returns ErrNotFound if no Watcher has been added
+// for fsys.
+func (p *Pool) Remove(fsys fs.FS) error {
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+
+	var removed []*poolWatcher
+	watchers := make([]*poolWatcher, 0, len(p.watchers))
+	for _, pw := range p.watchers {
+		if pw.fsys == fsys {
+			removed = append(removed, pw)
+			continue
+		}
+		watchers = append(watchers, pw)
+	}
+	p.watchers = watchers
+	running := p.running
+
+	p.mu.Unlock()
+
+	if len(removed) == 0 {
+		return ErrNotFound
+	}
+
+	if running {
+		for _, pw := range removed {
+			// Stop forwarding first so that the watcher does not block on a
+			// full channel while being closed.
+			close(pw.stop)
+			pw.w.Close()
+		}
+	}
+
+	return nil
+}
+
+// Start starts all watchers added to p. Watchers added afterwards are
+// started when they are added. Starting a running pool returns
+// ErrAlreadyStarted. If any watcher fails to start, all watchers started so
+// far are closed and replaced with new ones, so Start may be called again.
+func (p *Pool) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.running {
+		return ErrAlreadyStarted
+	}
+
+	for i, pw := range p.watchers {
+		if err := p.start(pw); err != nil {
+			p.reset(i)
+			return err
+		}
+	}
+
+	p.running = true
+
+	return nil
+}
+
+// reset closes the first n watchers of p, which have been started by Start,
+// and replaces them with new ones that have not been started yet. A watcher
+// that cannot be recreated is removed from p. reset must be called with mu
+// being held.
+func (p *Pool) reset(n int) {
+	watchers := make([]*poolWatcher, 0, len(p.watchers))
+
+	for i, pw := range p.watchers {
+		if i >= n {
+			watchers = append(watchers, pw)
+			continue
+		}
+
+		close(pw.stop)
+		pw.w.Close()
+
+		w, err := New(pw.fsys, pw.pat, p.interval, p.opts...)
+		if err != nil {
+			continue
+		}
+		watchers = append(watchers, &poolWatcher{fsys: pw.fsys, pat: pw.pat, w: w})
+	}
+
+	p.watchers = watchers
+}
+
+// Close closes all watchers. Events and errors not yet received from C or
+// Errors are discarded. Both C and Errors are closed before Close returns.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	watchers := p.watchers
+	running := p.running
+	p.mu.Unlock()
+
+	// Stop forwarding first so that no watcher blocks on a full channel while
+	// being closed.
+	close(p.done)
+
+	if running {
+		for _, pw := range watchers {
+			pw.w.Close()
+		}
+	}
+
+	p.wg.Wait()
+
+	close(p.c)
+	close(p.errors)
+}
+
+// start starts pw's Watcher and forwards its events and errors to p's
+// channels. It must be called with mu being held.
+func (p *Pool) start(pw *poolWatcher) error {
+	if err := pw.w.Start(); err != nil {
+		return err
+	}
+
+	pw.stop = make(chan struct{})
+	p.wg.Add(1)
+	go p.forward(pw)
+
+	return nil
+}
+
+// forward forwards all events and errors from pw's Watcher until both of its
+// channels have been closed. Once p is closed or pw is removed, events and
+// errors are discarded.
+func (p *Pool) forward(pw *poolWatcher) {
+	defer p.wg.Done()
+
+	c, errs := pw.w.C(), pw.w.ErrorsChan()
+
+	for c != nil || errs != nil {
+		select {
+		case evt, ok := <-c:
+			if !ok {
+				c = nil
+				continue
+			}
+
+			select {
+			case p.c <- evt:
+			case <-p.done:
+			case <-pw.stop:
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+
+			select {
+			case p.errors <- err:
+			case <-p.done:
+			case <-pw.stop:
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package globwatch_test
+
+import (
+	"fmt"
+	"io/fs"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestPool(t *testing.T) {
+	src := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+	docs := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("README.md", "# README"),
+	))
+
+	pool := globwatch.NewPool(time.Millisecond)
+
+	if err := pool.Add(src, "**/*.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, pool.Add(src, `**/*\.go`)).Is(Error(globwatch.ErrDuplicate))
+
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ExpectThat(t, pool.Start()).Is(Error(globwatch.ErrAlreadyStarted))
+
+	src.Touch("main_test.go")
+	evt := <-pool.C()
+	ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+	ExpectThat(t, evt.Path).Is(Equal("main_test.go"))
+
+	// Adding a watcher to a running pool starts it immediately.
+	if err := pool.Add(docs, "**/*.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	docs.Touch("CHANGELOG.md")
+	evt = <-pool.C()
+	ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+	ExpectThat(t, evt.Path).Is(Equal("CHANGELOG.md"))
+
+	ExpectThat(t, pool.Remove(src)).Is(NoError())
+	ExpectThat(t, pool.Remove(src)).Is(Error(globwatch.ErrNotFound))
+}
+
+// flakyFS wraps an fs.FS and fails to open any file while failing is set.
+type flakyFS struct {
+	fs.FS
+	failing atomic.Bool
+}
+
+func (f *flakyFS) Open(name string) (fs.File, error) {
+	if f.failing.Load() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return f.FS.Open(name)
+}
+
+func TestPool_Start_retry(t *testing.T) {
+	src := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+	docs := &flakyFS{FS: fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("README.md", "# README"),
+	))}
+	docs.failing.Store(true)
+
+	pool := globwatch.NewPool(time.Millisecond)
+
+	if err := pool.Add(src, "**/*.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add(docs, "**/*.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, pool.Start()).Is(Error(fs.ErrInvalid))
+
+	docs.failing.Store(false)
+
+	ExpectThat(t, pool.Start()).Is(NoError())
+	defer pool.Close()
+
+	src.Touch("main.go")
+	evt := <-pool.C()
+	ExpectThat(t, evt.Type).Is(Equal(globwatch.Modified))
+	ExpectThat(t, evt.Path).Is(Equal("main.go"))
+}
+
+func TestPool_Remove(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+		fsmock.TextFile("README.md", "# README"),
+	))
+	other := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+
+	pool := globwatch.NewPool(time.Millisecond)
+	defer pool.Close()
+
+	for _, pat := range []string{"**/*.go", "**/*.md"} {
+		if err := pool.Add(fsys, pat); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Add(other, "**/*.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fill up the pool's channel so that forwarding blocks.
+	for i := 0; i < 20; i++ {
+		fsys.Touch(fmt.Sprintf("file%02d.go", i))
+		fsys.Touch(fmt.Sprintf("file%02d.md", i))
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	removed := make(chan error)
+	go func() {
+		removed <- pool.Remove(fsys)
+	}()
+
+	select {
+	case err := <-removed:
+		ExpectThat(t, err).Is(NoError())
+	case <-time.After(5 * time.Second):
+		t.Fatal("Remove blocked")
+	}
+
+	// Both watchers for fsys have been removed.
+	ExpectThat(t, pool.Remove(fsys)).Is(Error(globwatch.ErrNotFound))
+	ExpectThat(t, pool.Remove(other)).Is(NoError())
+}
+
+func TestPool_Close(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+
+	pool := globwatch.NewPool(time.Millisecond)
+
+	if err := pool.Add(fsys, "**/*.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Close()
+
+	_, ok := <-pool.C()
+	ExpectThat(t, ok).Is(Equal(false))
+	_, ok = <-pool.Errors()
+	ExpectThat(t, ok).Is(Equal(false))
+
+	ExpectThat(t, pool.Add(fsys, "**/*.go")).Is(Error(globwatch.ErrClosed))
+	ExpectThat(t, pool.Remove(fsys)).Is(Error(globwatch.ErrClosed))
+}
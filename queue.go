@@ -0,0 +1,191 @@
+package globwatch
+
+import (
+	"sync"
+)
+
+// eventQueue implements a ring buffer of events. It decouples change
+// detection from consumers of C: a dedicated goroutine moves events from the
+// queue to C while change detection only blocks (or drops events) once the
+// queue is full.
+type eventQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []Event
+	head   int
+	n      int
+	policy ChannelPolicy
+	closed bool
+	done   chan struct{}
+	// An event taken from the queue that could not be forwarded before the
+	// queue has been closed
+	unsent *Event
+}
+
+func newEventQueue(size int, policy ChannelPolicy) *eventQueue {
+	q := &eventQueue{
+		buf:    make([]Event, size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// push appends evt to q applying q's policy if q is full. It returns false
+// if an event has been dropped.
+func (q *eventQueue) push(evt Event) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.policy == BlockUntilDrained {
+		for q.n == len(q.buf) && !q.closed {
+			q.cond.Wait()
+		}
+	}
+
+	if q.closed {
+		return true
+	}
+
+	dropped := false
+	if q.n == len(q.buf) {
+		if q.policy == DropNewest {
+			return false
+		}
+
+		// Overwrite the oldest event.
+		q.head = (q.head + 1) % len(q.buf)
+		q.n--
+		dropped = true
+	}
+
+	q.buf[(q.head+q.n)%len(q.buf)] = evt
+	q.n++
+	q.cond.Broadcast()
+
+	return !dropped
+}
+
+// pop removes and returns the oldest event from q. It blocks until an event
+// is available. It returns false once q has been closed.
+func (q *eventQueue) pop() (Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.n == 0 && !q.closed {
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		return Event{}, false
+	}
+
+	evt := q.buf[q.head]
+	q.buf[q.head] = Event{}
+	q.head = (q.head + 1) % len(q.buf)
+	q.n--
+	q.cond.Broadcast()
+
+	return evt, true
+}
+
+// len returns the number of events queued in q.
+func (q *eventQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.n
+}
+
+// close closes q. Any blocked push or pop returns. Events still queued are
+// retained and can be obtained using remaining.
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	q.closed = true
+	close(q.done)
+	q.cond.Broadcast()
+}
+
+// forward moves events from q to c until q is closed.
+func (q *eventQueue) forward(c chan<- Event) {
+	for {
+		evt, ok := q.pop()
+		if !ok {
+			return
+		}
+
+		select {
+		case c <- evt:
+		case <-q.done:
+			q.mu.Lock()
+			q.unsent = &evt
+			q.mu.Unlock()
+			return
+		}
+	}
+}
+
+// remaining removes and returns all events left in q after it has been
+// closed.
+func (q *eventQueue) remaining() []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	evts := make([]Event, 0, q.n+1)
+	if q.unsent != nil {
+		evts = append(evts, *q.unsent)
+		q.unsent = nil
+	}
+
+	for ; q.n > 0; q.n-- {
+		evts = append(evts, q.buf[q.head])
+		q.buf[q.head] = Event{}
+		q.head = (q.head + 1) % len(q.buf)
+	}
+
+	return evts
+}
+
+// Drain returns all events emitted by w that have not been received from C
+// before w has been closed. This includes events buffered in C as well as
+// events queued due to WithEventQueueSize. Drain must be called after Close
+// returned or the context passed to StartContext has been canceled and w has
+// stopped; it returns ErrNotClosed otherwise. Each event is returned only
+// once; consecutive calls return an empty slice.
+func (w *Watcher) Drain() ([]Event, error) {
+	select {
+	case <-w.closed:
+	default:
+		return nil, ErrNotClosed
+	}
+
+	evts := make([]Event, 0, len(w.c))
+	for evt := range w.c {
+		evts = append(evts, evt)
+	}
+
+	if w.cSub.q != nil {
+		evts = append(evts, w.cSub.q.remaining()...)
+	}
+
+	return evts, nil
+}
+
+// BufferedEventCount returns the number of events emitted by w that have not
+// yet been received from C. This includes events queued due to
+// WithEventQueueSize as well as events buffered in C.
+func (w *Watcher) BufferedEventCount() int {
+	n := len(w.c)
+	if w.cSub.q != nil {
+		n += w.cSub.q.len()
+	}
+	return n
+}
@@ -0,0 +1,217 @@
+package globwatch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestEventQueue(t *testing.T) {
+	evts := func(paths ...string) []Event {
+		r := make([]Event, 0, len(paths))
+		for _, p := range paths {
+			r = append(r, Event{Type: Created, Path: p})
+		}
+		return r
+	}
+
+	drain := func(q *eventQueue) []Event {
+		r := make([]Event, 0)
+		for q.len() > 0 {
+			evt, _ := q.pop()
+			r = append(r, evt)
+		}
+		return r
+	}
+
+	t.Run("DropOldest", func(t *testing.T) {
+		q := newEventQueue(2, DropOldest)
+		var delivered []bool
+		for _, evt := range evts("a.go", "b.go", "c.go") {
+			delivered = append(delivered, q.push(evt))
+		}
+
+		ExpectThat(t, delivered).Is(DeepEqual([]bool{true, true, false}))
+		ExpectThat(t, drain(q)).Is(DeepEqual(evts("b.go", "c.go")))
+	})
+
+	t.Run("DropNewest", func(t *testing.T) {
+		q := newEventQueue(2, DropNewest)
+		var delivered []bool
+		for _, evt := range evts("a.go", "b.go", "c.go") {
+			delivered = append(delivered, q.push(evt))
+		}
+
+		ExpectThat(t, delivered).Is(DeepEqual([]bool{true, true, false}))
+		ExpectThat(t, drain(q)).Is(DeepEqual(evts("a.go", "b.go")))
+	})
+
+	t.Run("BlockUntilDrained", func(t *testing.T) {
+		q := newEventQueue(2, BlockUntilDrained)
+		q.push(Event{Type: Created, Path: "a.go"})
+		q.push(Event{Type: Created, Path: "b.go"})
+
+		done := make(chan struct{})
+		go func() {
+			q.push(Event{Type: Created, Path: "c.go"})
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("expected push to block")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		evt, ok := q.pop()
+		ExpectThat(t, ok).Is(Equal(true))
+		ExpectThat(t, evt.Path).Is(Equal("a.go"))
+		<-done
+
+		ExpectThat(t, drain(q)).Is(DeepEqual(evts("b.go", "c.go")))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		q := newEventQueue(2, BlockUntilDrained)
+		q.push(Event{Type: Created, Path: "a.go"})
+		q.close()
+
+		_, ok := q.pop()
+		ExpectThat(t, ok).Is(Equal(false))
+		ExpectThat(t, q.push(Event{Type: Created, Path: "b.go"})).Is(Equal(true))
+	})
+}
+
+func TestWatcher_WithEventQueueSize(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := New(fsys, "**/*.go", time.Hour, WithEventBufferSize(0), WithEventQueueSize(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody receives from C but emitting does not block.
+	for i := 0; i < 10; i++ {
+		watcher.emit(Event{Type: Created, Path: fmt.Sprintf("%d.go", i)})
+	}
+
+	// One event may have been taken from the queue and is waiting to be sent.
+	ExpectThat(t, watcher.BufferedEventCount() >= 9).Is(Equal(true))
+
+	for i := 0; i < 10; i++ {
+		ExpectThat(t, (<-watcher.C()).Path).Is(Equal(fmt.Sprintf("%d.go", i)))
+	}
+
+	ExpectThat(t, watcher.BufferedEventCount()).Is(Equal(0))
+
+	watcher.emit(Event{Type: Created, Path: "unread.go"})
+	watcher.Close()
+
+	_, ok := <-watcher.C()
+	ExpectThat(t, ok).Is(Equal(false))
+
+	evts, err := watcher.Drain()
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(evts)).Is(Equal(1))
+	ExpectThat(t, evts[0].Path).Is(Equal("unread.go"))
+
+	_, err = New(fsys, "**/*.go", time.Second, WithEventQueueSize(-1))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+}
+
+// benchmarkEmit measures the time spent emitting 100 events to a consumer
+// that takes 100µs to process each event.
+func benchmarkEmit(b *testing.B, opts ...Option) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := New(fsys, "**/*.go", time.Hour, opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		b.Fatal(err)
+	}
+	defer watcher.Close()
+
+	received := make(chan struct{}, 100)
+	go func() {
+		for range watcher.C() {
+			time.Sleep(100 * time.Microsecond)
+			received <- struct{}{}
+		}
+	}()
+
+	evt := Event{Type: Modified, Path: "main.go"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100; j++ {
+			watcher.emit(evt)
+		}
+
+		b.StopTimer()
+		for j := 0; j < 100; j++ {
+			<-received
+		}
+		b.StartTimer()
+	}
+}
+
+func BenchmarkEmit_channel(b *testing.B) {
+	benchmarkEmit(b)
+}
+
+func BenchmarkEmit_queue(b *testing.B) {
+	benchmarkEmit(b, WithEventQueueSize(100))
+}
+
+func TestWatcher_Drain(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"channel": nil,
+		"queue":   {WithEventBufferSize(1), WithEventQueueSize(10)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fsys := fsmock.New(fsmock.NewDir("",
+				fsmock.EmptyFile("go.mod"),
+			))
+
+			watcher, err := New(fsys, "**/*.go", time.Hour, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := watcher.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = watcher.Drain()
+			ExpectThat(t, err).Is(Error(ErrNotClosed))
+
+			fsys.Touch("main.go")
+			fsys.Touch("main_test.go")
+			watcher.scan(watcher.ctx)
+
+			watcher.Close()
+
+			evts, err := watcher.Drain()
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+				{Type: Created, Path: "main.go"},
+				{Type: Created, Path: "main_test.go"},
+			}))
+
+			evts, err = watcher.Drain()
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, len(evts)).Is(Equal(0))
+		})
+	}
+}
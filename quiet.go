@@ -0,0 +1,70 @@
+package globwatch
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// AfterQuiet subscribes to the events emitted by w and accumulates them. Once
+// no new event has been received for the quiet duration, fn is called with
+// all events accumulated since the last call. Use it to run a build or test
+// suite once a burst of changes has settled. fn is called in its own
+// goroutine, so calls may overlap if fn takes longer than it takes for the
+// next burst to settle.
+//
+// Closing the returned io.Closer ends the subscription; events accumulated
+// but not yet passed to fn are discarded. The same applies when w is closed.
+func AfterQuiet(w *Watcher, quiet time.Duration, fn func([]Event)) io.Closer {
+	events, unsubscribe := w.Subscribe()
+
+	q := &quietCloser{
+		unsubscribe: unsubscribe,
+		done:        make(chan struct{}),
+	}
+
+	go q.run(events, quiet, fn)
+
+	return q
+}
+
+// quietCloser implements the io.Closer returned from AfterQuiet.
+type quietCloser struct {
+	unsubscribe func()
+	done        chan struct{}
+	once        sync.Once
+}
+
+// run accumulates events until events is closed.
+func (q *quietCloser) run(events <-chan Event, quiet time.Duration, fn func([]Event)) {
+	defer close(q.done)
+
+	var pending []Event
+	timer := time.NewTimer(quiet)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+
+			pending = append(pending, evt)
+			timer.Reset(quiet)
+
+		case <-timer.C:
+			go fn(pending)
+			pending = nil
+		}
+	}
+}
+
+// Close ends the subscription and waits for the accumulating goroutine to
+// finish. It always returns nil.
+func (q *quietCloser) Close() error {
+	q.once.Do(q.unsubscribe)
+	<-q.done
+	return nil
+}
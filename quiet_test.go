@@ -0,0 +1,68 @@
+package globwatch
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestAfterQuiet(t *testing.T) {
+	watcher, err := New(fsmock.New(fsmock.NewDir("")), "**/*.go", time.Second, WithEventBufferSize(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var calls [][]Event
+
+	closer := AfterQuiet(watcher, 20*time.Millisecond, func(evts []Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, evts)
+	})
+
+	for i := 0; i < 10; i++ {
+		watcher.emit(Event{Type: Created, Path: fmt.Sprintf("%d.go", i)})
+		time.Sleep(500 * time.Microsecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	ExpectThat(t, closer.Close()).Is(NoError())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ExpectThat(t, len(calls)).Is(Equal(1))
+	ExpectThat(t, len(calls[0])).Is(Equal(10))
+	for i, evt := range calls[0] {
+		ExpectThat(t, evt.Path).Is(Equal(fmt.Sprintf("%d.go", i)))
+	}
+}
+
+func TestAfterQuiet_close(t *testing.T) {
+	watcher, err := New(fsmock.New(fsmock.NewDir("")), "**/*.go", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	called := make(chan struct{}, 1)
+	closer := AfterQuiet(watcher, 10*time.Millisecond, func([]Event) {
+		called <- struct{}{}
+	})
+
+	watcher.emit(Event{Type: Created, Path: "main.go"})
+	ExpectThat(t, closer.Close()).Is(NoError())
+	ExpectThat(t, closer.Close()).Is(NoError())
+
+	select {
+	case <-called:
+		t.Fatal("fn called after close")
+	case <-time.After(30 * time.Millisecond):
+	}
+}
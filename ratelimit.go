@@ -0,0 +1,73 @@
+package globwatch
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket implements a simple token bucket rate limiter. The bucket holds
+// up to capacity tokens and is refilled continuously at a rate of capacity
+// tokens per period. It is safe for concurrent use.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	period   time.Duration
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+func newTokenBucket(n int, per time.Duration) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(n),
+		period:   per,
+		tokens:   float64(n),
+		now:      time.Now,
+	}
+}
+
+// allow consumes a token and returns true if one is available. It returns
+// false if the bucket is empty.
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	if !b.last.IsZero() && b.period > 0 {
+		b.tokens += b.capacity * float64(now.Sub(b.last)) / float64(b.period)
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// DroppedEvents returns the number of events dropped due to the rate limit
+// configured with WithEventRateLimit.
+func (w *Watcher) DroppedEvents() uint64 {
+	return w.droppedEvents.Load()
+}
+
+// rateLimited reports whether evt exceeds the configured rate limit. A limited
+// event is counted and passed to the drop handler.
+func (w *Watcher) rateLimited(evt Event) bool {
+	if w.limiter == nil || w.limiter.allow() {
+		return false
+	}
+
+	w.droppedEvents.Add(1)
+
+	if w.dropHandler != nil {
+		w.dropHandler(evt)
+	}
+
+	return true
+}
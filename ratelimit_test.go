@@ -0,0 +1,103 @@
+package globwatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	b := newTokenBucket(2, time.Second)
+	b.now = func() time.Time { return now }
+
+	ExpectThat(t, b.allow()).Is(Equal(true))
+	ExpectThat(t, b.allow()).Is(Equal(true))
+	ExpectThat(t, b.allow()).Is(Equal(false))
+
+	now = now.Add(500 * time.Millisecond)
+	ExpectThat(t, b.allow()).Is(Equal(true))
+	ExpectThat(t, b.allow()).Is(Equal(false))
+
+	now = now.Add(time.Hour)
+	ExpectThat(t, b.allow()).Is(Equal(true))
+	ExpectThat(t, b.allow()).Is(Equal(true))
+	ExpectThat(t, b.allow()).Is(Equal(false))
+}
+
+func TestWatcher_rateLimit(t *testing.T) {
+	files := make([]fsmock.Entry, 0, 20)
+	for i := 0; i < 20; i++ {
+		files = append(files, fsmock.EmptyFile(fmt.Sprintf("file_%02d.go", i)))
+	}
+	fsys := fsmock.New(fsmock.NewDir("", files...))
+
+	var dropped []Event
+
+	watcher, err := New(fsys, "*.go", time.Second,
+		WithEventRateLimit(5, time.Hour),
+		WithDropHandler(func(evt Event) {
+			dropped = append(dropped, evt)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without an initial state every file is reported as created during the
+	// first tick.
+	watcher.detectChanges(context.Background())
+	close(watcher.c)
+
+	evts := make([]Event, 0, 20)
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, len(evts)).Is(Equal(5))
+	ExpectThat(t, len(dropped)).Is(Equal(15))
+	ExpectThat(t, watcher.DroppedEvents()).Is(Equal(uint64(15)))
+}
+
+func TestWithEventRateLimit_invalid(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	_, err := New(fsys, "*.go", time.Second, WithEventRateLimit(0, time.Second))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+
+	_, err = New(fsys, "*.go", time.Second, WithEventRateLimit(5, 0))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+}
+
+func TestWatcher_rateLimit_concurrent(t *testing.T) {
+	watcher, err := New(fsmock.New(fsmock.NewDir("")), "*.go", time.Second,
+		WithEventRateLimit(50, time.Hour),
+		WithEventBufferSize(200),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 2; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				watcher.emit(Event{Type: Created, Path: fmt.Sprintf("file_%d_%02d.go", g, i)})
+			}
+		}()
+	}
+	wg.Wait()
+
+	ExpectThat(t, len(watcher.c)).Is(Equal(50))
+	ExpectThat(t, watcher.DroppedEvents()).Is(Equal(uint64(150)))
+}
@@ -0,0 +1,177 @@
+// Package remote streams the events reported by a globwatch.Watcher to other
+// processes over TCP.
+//
+// Serve accepts connections and writes every event as a single line of JSON
+// to each of them. The JSON format is the one produced by
+// globwatch.Event.MarshalJSON extended with a timestamp field containing the
+// time the event has been sent:
+//
+//	{"type":"modified","path":"cmd/main.go",...,"timestamp":"2022-11-01T12:00:00Z"}
+//
+// Dial connects to such a server and receives the events.
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/halimath/globwatch"
+)
+
+// maxLineSize defines the maximum size of a single line read by Dial.
+const maxLineSize = 1 << 20
+
+// Serve listens on the TCP network address addr and streams all events
+// reported by w to every accepted connection. Each connection subscribes to
+// w independently (see globwatch.Watcher.Subscribe); the subscription is
+// canceled when the client closes the connection. Serve returns nil once w
+// has been closed or an error if listening or accepting fails.
+func Serve(w *globwatch.Watcher, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return serve(w, ln)
+}
+
+// serve accepts connections from ln until w is closed.
+func serve(w *globwatch.Watcher, ln net.Listener) error {
+	done, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	go func() {
+		for range done {
+		}
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		events, unsubscribe := w.Subscribe()
+		go serveConn(conn, events, unsubscribe)
+	}
+}
+
+// serveConn writes events to conn until events is closed or writing fails.
+func serveConn(conn net.Conn, events <-chan globwatch.Event, unsubscribe func()) {
+	defer conn.Close()
+	defer unsubscribe()
+
+	// Clients never send any data. Reading returns once the client closed
+	// the connection which ends the subscription.
+	go func() {
+		io.Copy(io.Discard, conn)
+		unsubscribe()
+	}()
+
+	for evt := range events {
+		data, err := marshal(evt, time.Now())
+		if err != nil {
+			continue
+		}
+
+		if _, err := conn.Write(data); err != nil {
+			return
+		}
+	}
+}
+
+// marshal marshals evt as a single line of JSON including the timestamp ts.
+func marshal(evt globwatch.Event, ts time.Time) ([]byte, error) {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := json.Marshal(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Insert the timestamp as the object's last field.
+	data = append(data[:len(data)-1], `,"timestamp":`...)
+	data = append(data, timestamp...)
+	data = append(data, '}', '\n')
+
+	return data, nil
+}
+
+// Dial connects to a server started with Serve listening on the TCP network
+// address addr. Received events are sent to the first returned channel; errors
+// decoding events or reading from the connection are sent to the second one.
+// Both channels are closed when the connection is closed. Calling the
+// returned function closes the connection. The timestamp sent along with each
+// event is not part of globwatch.Event and thus discarded.
+func Dial(addr string) (<-chan globwatch.Event, <-chan error, context.CancelFunc, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	events, errs, cancel := receive(conn)
+	return events, errs, cancel, nil
+}
+
+// receive reads events from conn until conn is closed or the returned
+// function is called.
+func receive(conn net.Conn) (<-chan globwatch.Event, <-chan error, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := make(chan globwatch.Event, 10)
+	errs := make(chan error, 10)
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+		defer cancel()
+
+		report := func(err error) {
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+			}
+		}
+
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(nil, maxLineSize)
+
+		for scanner.Scan() {
+			var evt globwatch.Event
+			if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+				report(fmt.Errorf("failed to decode event: %w", err))
+				continue
+			}
+
+			select {
+			case events <- evt:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			report(err)
+		}
+	}()
+
+	return events, errs, cancel
+}
@@ -0,0 +1,95 @@
+package remote
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestServeConn(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.NewDir("cmd",
+			fsmock.TextFile("main.go", "package main"),
+		),
+	))
+
+	watcher, err := globwatch.New(fsys, "**/*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, client := net.Pipe()
+
+	subscription, unsubscribe := watcher.Subscribe()
+	go serveConn(server, subscription, unsubscribe)
+
+	events, errs, cancel := receive(client)
+	defer cancel()
+
+	go func() {
+		for range watcher.C() {
+		}
+	}()
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.Touch("cmd/main_test.go")
+
+	select {
+	case evt := <-events:
+		ExpectThat(t, evt.Type).Is(Equal(globwatch.Created))
+		ExpectThat(t, evt.Path).Is(Equal("cmd/main_test.go"))
+		ExpectThat(t, evt.WatcherID).Is(Equal(watcher.ID()))
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+
+	watcher.Close()
+
+	select {
+	case _, ok := <-events:
+		ExpectThat(t, ok).Is(Equal(false))
+	case <-time.After(time.Second):
+		t.Fatal("events not closed after watcher has been closed")
+	}
+}
+
+func TestReceive_invalid(t *testing.T) {
+	server, client := net.Pipe()
+
+	events, errs, cancel := receive(client)
+	defer cancel()
+
+	go func() {
+		server.Write([]byte("invalid\n"))
+		server.Write([]byte(`{"type":"deleted","path":"main.go","timestamp":"2022-11-01T12:00:00Z"}` + "\n"))
+		server.Close()
+	}()
+
+	ExpectThat(t, <-errs).Is(NotNil())
+	ExpectThat(t, <-events).Is(DeepEqual(globwatch.Event{Type: globwatch.Deleted, Path: "main.go"}))
+
+	_, ok := <-events
+	ExpectThat(t, ok).Is(Equal(false))
+}
+
+func TestMarshal(t *testing.T) {
+	ts := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := marshal(globwatch.Event{Type: globwatch.Modified, Path: "main.go"}, ts)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, string(data)).Is(Equal(`{"type":"modified","path":"main.go","timestamp":"2022-11-01T12:00:00Z"}` + "\n"))
+
+	var got map[string]any
+	ExpectThat(t, json.Unmarshal(data, &got)).Is(NoError())
+}
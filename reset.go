@@ -0,0 +1,49 @@
+package globwatch
+
+import (
+	"time"
+)
+
+// Reset discards all tracked state and rescans the watched files from
+// scratch. A Created event is emitted for every file found as if it had been
+// seen for the first time. Use Reset when the tracked state is known to be
+// invalid, i.e. after a large number of files changed at once.
+//
+// Reset is safe to call while w is running; it is serialized with the
+// regular change detection. It returns ErrClosed if w is not running or is
+// being closed or the error that caused the rescan to fail.
+func (w *Watcher) Reset() error {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	if !w.running.Load() || w.closing.Load() {
+		return ErrClosed
+	}
+
+	w.mu.Lock()
+	w.modtimes = make(map[string]time.Time)
+	if w.truncation {
+		w.filesizes = make(map[string]int64)
+	}
+	if w.newHash != nil {
+		w.checksums = make(map[string][]byte)
+	}
+	if w.deduplication {
+		w.seenModified = make(map[string]time.Time)
+	}
+	w.shadow = nil
+	w.mu.Unlock()
+
+	return w.detectChanges(w.ctx)
+}
+
+// awaitScans marks w as closing and waits for any scan started by Reset to
+// complete. Once awaitScans returns, no further scans will be started. Events
+// pending due to a coalesce window are emitted immediately.
+func (w *Watcher) awaitScans() {
+	w.closing.Store(true)
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	w.flushPending()
+}
@@ -0,0 +1,150 @@
+package globwatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+)
+
+// root is an additional filesystem watched by a Watcher. Each root is
+// watched by its own Watcher which keeps track of the root's files.
+type root struct {
+	fsys    fs.FS
+	w       *Watcher
+	started bool
+}
+
+// AddRoot adds fsys as an additional filesystem to watch for files matching
+// pat. Changes are detected using w's interval and reported via w's
+// channels and handlers just like changes of w's own filesystem. Events
+// reported for fsys carry fsys as their Root, so events can be attributed to
+// a filesystem even if paths are equal. The files of fsys are tracked
+// separately from w's own files and are not returned from Files or
+// Snapshot.
+//
+// AddRoot may be called before or while w is running. fsys is used to
+// identify the root when calling RemoveRoot so its dynamic type must be
+// comparable. AddRoot returns ErrDuplicate if fsys has already been added
+// and ErrClosed if w has been closed.
+func (w *Watcher) AddRoot(fsys fs.FS, pat string) error {
+	if fsys == nil || !reflect.TypeOf(fsys).Comparable() {
+		return fmt.Errorf("%w: root filesystem must be comparable", ErrInvalidOption)
+	}
+
+	child, err := New(fsys, pat, w.interval, WithCallbacksOnly(true))
+	if err != nil {
+		return err
+	}
+
+	child.OnEvent(func(evt Event) {
+		evt.Root = fsys
+
+		// Serialize with w's own change detection.
+		w.scanMu.Lock()
+		defer w.scanMu.Unlock()
+
+		w.emit(evt)
+	})
+	child.OnError(w.reportError)
+
+	w.rootsMu.Lock()
+	defer w.rootsMu.Unlock()
+
+	if w.rootsDone {
+		return ErrClosed
+	}
+
+	for _, r := range w.roots {
+		if r.fsys == fsys {
+			return ErrDuplicate
+		}
+	}
+
+	r := &root{fsys: fsys, w: child}
+	if w.rootsStarted {
+		if err := child.StartContext(w.rootsCtx); err != nil {
+			return err
+		}
+		r.started = true
+	}
+
+	w.roots = append(w.roots, r)
+
+	return nil
+}
+
+// RemoveRoot stops watching fsys previously added using AddRoot. No Deleted
+// events are reported for the root's files. RemoveRoot returns ErrNotFound if
+// fsys has not been added and ErrClosed if w has been closed.
+func (w *Watcher) RemoveRoot(fsys fs.FS) error {
+	w.rootsMu.Lock()
+
+	if w.rootsDone {
+		w.rootsMu.Unlock()
+		return ErrClosed
+	}
+
+	var removed *root
+	roots := make([]*root, 0, len(w.roots))
+	for _, r := range w.roots {
+		if removed == nil && r.fsys == fsys {
+			removed = r
+			continue
+		}
+		roots = append(roots, r)
+	}
+	w.roots = roots
+
+	w.rootsMu.Unlock()
+
+	if removed == nil {
+		return ErrNotFound
+	}
+
+	if removed.started {
+		removed.w.Close()
+	}
+
+	return nil
+}
+
+// startRoots starts watching all roots added before w has been started.
+// Roots added afterwards are started when being added.
+func (w *Watcher) startRoots(ctx context.Context) error {
+	w.rootsMu.Lock()
+	defer w.rootsMu.Unlock()
+
+	for _, r := range w.roots {
+		if err := r.w.StartContext(ctx); err != nil {
+			for _, started := range w.roots {
+				if started.started {
+					started.w.Close()
+					started.started = false
+				}
+			}
+			return err
+		}
+		r.started = true
+	}
+
+	w.rootsCtx = ctx
+	w.rootsStarted = true
+
+	return nil
+}
+
+// stopRoots stops watching all roots. Roots can no longer be added
+// afterwards.
+func (w *Watcher) stopRoots() {
+	w.rootsMu.Lock()
+	w.rootsDone = true
+	roots := w.roots
+	w.rootsMu.Unlock()
+
+	for _, r := range roots {
+		if r.started {
+			r.w.Close()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package globwatch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatcher_AddRoot(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+	lib := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+	tools := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+	))
+
+	watcher, err := globwatch.New(fsys, "*.go", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.AddRoot(lib, "*.go")).Is(NoError())
+	ExpectThat(t, watcher.AddRoot(lib, "*.go")).Is(Error(globwatch.ErrDuplicate))
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	// Added while running
+	ExpectThat(t, watcher.AddRoot(tools, "*.go")).Is(NoError())
+	time.Sleep(10 * time.Millisecond)
+
+	receive := func() globwatch.Event {
+		t.Helper()
+
+		select {
+		case evt := <-watcher.C():
+			return globwatch.Event{Type: evt.Type, Path: evt.Path, Root: evt.Root}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+			return globwatch.Event{}
+		}
+	}
+
+	lib.Touch("lib.go")
+	ExpectThat(t, receive()).Is(DeepEqual(globwatch.Event{Type: globwatch.Created, Path: "lib.go", Root: lib}))
+
+	tools.Touch("main.go")
+	ExpectThat(t, receive()).Is(DeepEqual(globwatch.Event{Type: globwatch.Modified, Path: "main.go", Root: tools}))
+
+	fsys.Touch("main.go")
+	ExpectThat(t, receive()).Is(DeepEqual(globwatch.Event{Type: globwatch.Modified, Path: "main.go"}))
+
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"main.go"}))
+
+	ExpectThat(t, watcher.RemoveRoot(lib)).Is(NoError())
+	ExpectThat(t, watcher.RemoveRoot(lib)).Is(Error(globwatch.ErrNotFound))
+
+	lib.Touch("main.go")
+	tools.Touch("tool.go")
+	ExpectThat(t, receive()).Is(DeepEqual(globwatch.Event{Type: globwatch.Created, Path: "tool.go", Root: tools}))
+}
@@ -0,0 +1,252 @@
+package globwatch
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ChannelPolicy defines how events are handled when sending to a full
+// channel.
+type ChannelPolicy int
+
+const (
+	// BlockUntilDrained blocks change detection until the event has been
+	// received.
+	BlockUntilDrained ChannelPolicy = iota
+	// DropOldest removes the oldest event from the channel to make room for
+	// the new event.
+	DropOldest
+	// DropNewest discards the new event.
+	DropNewest
+)
+
+// subscriber is a single receiver of events emitted by a Watcher.
+type subscriber struct {
+	mu     sync.Mutex
+	c      chan Event
+	policy ChannelPolicy
+	closed bool
+
+	// Optional queue buffering events sent to c
+	q          *eventQueue
+	forwarding sync.WaitGroup
+}
+
+// send sends evt to s applying s' policy if its channel is full. It returns
+// false if an event has been dropped.
+func (s *subscriber) send(evt Event) bool {
+	if s.q != nil {
+		return s.q.push(evt)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return true
+	}
+
+	switch s.policy {
+	case DropOldest:
+		dropped := false
+		for {
+			select {
+			case s.c <- evt:
+				return !dropped
+			default:
+			}
+
+			// The channel is full. Remove the oldest event unless a receiver
+			// did so in the meantime.
+			select {
+			case <-s.c:
+				dropped = true
+			default:
+			}
+		}
+
+	case DropNewest:
+		select {
+		case s.c <- evt:
+			return true
+		default:
+			return false
+		}
+
+	default:
+		s.c <- evt
+		return true
+	}
+}
+
+// startForwarding starts moving events from s' queue to its channel. It is
+// a no-op if s has no queue.
+func (s *subscriber) startForwarding() {
+	if s.q == nil {
+		return
+	}
+
+	s.forwarding.Add(1)
+	go func() {
+		defer s.forwarding.Done()
+		s.q.forward(s.c)
+	}()
+}
+
+// close closes s' channel. Events still queued are retained in s' queue. It
+// is safe to call close multiple times.
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+
+		if s.q != nil {
+			s.q.close()
+			s.forwarding.Wait()
+		}
+
+		close(s.c)
+	}
+}
+
+// Subscribe registers a new receiver of events and returns a channel
+// receiving all events emitted by w from now on. Each subscriber receives
+// its own copy of every event. Events are never sent to a subscriber in a
+// blocking fashion: if the subscriber's channel is full the event is dropped
+// and ErrEventDropped is reported via the errors channel.
+//
+// The returned function unsubscribes the channel and closes it. The channel
+// is also closed when w is closed.
+func (w *Watcher) Subscribe() (<-chan Event, func()) {
+	s := &subscriber{
+		c:      make(chan Event, 10),
+		policy: DropNewest,
+	}
+
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+
+	if w.subsDone {
+		s.close()
+		return s.c, func() {}
+	}
+
+	w.subs = append(w.subs, s)
+
+	return s.c, func() { w.unsubscribe(s) }
+}
+
+func (w *Watcher) unsubscribe(s *subscriber) {
+	w.subsMu.Lock()
+
+	// Always create a new slice so that emit can safely iterate over a
+	// previously obtained one.
+	subs := make([]*subscriber, 0, len(w.subs))
+	for _, o := range w.subs {
+		if o != s {
+			subs = append(subs, o)
+		}
+	}
+	w.subs = subs
+
+	w.subsMu.Unlock()
+
+	s.close()
+}
+
+// emit sends evt to all subscribers and event handlers.
+func (w *Watcher) emit(evt Event) {
+	if evt.Root == nil {
+		evt.Path = w.externalPath(evt.Path)
+	}
+
+	if w.rateLimited(evt) {
+		return
+	}
+
+	evt.WatcherID = w.id
+	evt.SeqNum = w.seqNum.Add(1)
+
+	w.logEvent(evt)
+
+	if w.journal != nil {
+		w.journal.add(evt)
+	}
+
+	skipC := w.dispatchEvent(evt) && w.callbacksOnly
+
+	w.subsMu.RLock()
+	subs := w.subs
+	w.subsMu.RUnlock()
+
+	for _, s := range subs {
+		if skipC && s.c == w.c {
+			continue
+		}
+
+		if !s.send(evt) {
+			w.channelDrops.Add(1)
+
+			if s.c == w.c {
+				// Events dropped from C due to the policy set with
+				// WithFullChannelPolicy are only counted.
+				continue
+			}
+
+			w.log(slog.LevelWarn, "event dropped due to full channel",
+				slog.String("type", evt.Type.String()),
+				slog.String("path", evt.Path),
+			)
+			w.reportError(fmt.Errorf("%w: %s %s", ErrEventDropped, evt.Type, evt.Path))
+		}
+	}
+
+	w.dispatchTypedEvent(evt)
+}
+
+// DroppedEventCount returns the number of events dropped because the
+// receiving channel was full. This includes events dropped from C due to the
+// policy set with WithFullChannelPolicy as well as events dropped from
+// channels returned from Subscribe.
+func (w *Watcher) DroppedEventCount() uint64 {
+	return w.channelDrops.Load()
+}
+
+// closeSubscribers closes all subscriber channels including C.
+func (w *Watcher) closeSubscribers() {
+	w.subsMu.Lock()
+	subs := w.subs
+	w.subs = nil
+	w.subsDone = true
+	w.subsMu.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+// sendHeartbeat sends a Heartbeat event to C. Other subscribers and event
+// handlers do not receive heartbeats. See WithHeartbeat.
+func (w *Watcher) sendHeartbeat() {
+	if w.callbacksOnly {
+		return
+	}
+
+	w.subsMu.RLock()
+	subs := w.subs
+	w.subsMu.RUnlock()
+
+	for _, s := range subs {
+		if s.c != w.c {
+			continue
+		}
+
+		if !s.send(Event{Type: Heartbeat, WatcherID: w.id}) {
+			w.channelDrops.Add(1)
+		}
+		return
+	}
+}
@@ -0,0 +1,119 @@
+package globwatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/halimath/globwatch/pattern"
+)
+
+// defaultMaxSymlinkDepth is the default number of symlinks followed to reach
+// a single file or directory. It matches the limit used by Linux.
+const defaultMaxSymlinkDepth = 40
+
+// symlinkWalk contains the state of a single walk following symlinks.
+type symlinkWalk struct {
+	entries []pattern.Entry
+	werrs   *walkErrors
+	// Keys of the directories walked to reach the current directory
+	visited map[uint64]struct{}
+}
+
+// globFollow works like glob but follows symbolic links. Entries for
+// symlinked files carry the symlink's path and the target's file info.
+// Symlinked directories are walked as if they were regular directories.
+//
+// A directory already walked to reach the current directory is not entered
+// again; a symlink pointing to it is reported as ErrSymlinkCycle. The same
+// error is reported for a symlink exceeding the depth set with
+// WithMaxSymlinkDepth. Both kinds of errors do not terminate the walk.
+func (w *Watcher) globFollow(ctx context.Context, werrs *walkErrors) ([]pattern.Entry, error) {
+	sw := &symlinkWalk{
+		entries: make([]pattern.Entry, 0),
+		werrs:   werrs,
+		visited: make(map[uint64]struct{}),
+	}
+
+	info, err := fs.Stat(w.fsys, ".")
+	if err != nil {
+		return sw.entries, w.handleWalkError(".", err, werrs)
+	}
+
+	err = w.walkFollow(ctx, sw, ".", info, 0)
+	return sw.entries, err
+}
+
+// walkFollow walks dir following symbolic links. info is dir's (resolved)
+// file info and links is the number of symlinks followed to reach dir.
+func (w *Watcher) walkFollow(ctx context.Context, sw *symlinkWalk, dir string, info fs.FileInfo, links int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if key, ok := fileKey(info); ok {
+		sw.visited[key] = struct{}{}
+		defer delete(sw.visited, key)
+	}
+
+	dirEntries, err := fs.ReadDir(w.fsys, dir)
+	if err != nil {
+		return w.handleWalkError(dir, err, sw.werrs)
+	}
+
+	for _, d := range dirEntries {
+		p := path.Join(dir, d.Name())
+		l := links
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			l++
+			if l > w.maxSymlinkDepth {
+				sw.werrs.errs = append(sw.werrs.errs, fmt.Errorf("%w: %s: maximum depth of %d exceeded", ErrSymlinkCycle, p, w.maxSymlinkDepth))
+				continue
+			}
+
+			// Stat follows the symlink.
+			i, err := fs.Stat(w.fsys, p)
+			if err != nil {
+				if err := w.handleWalkError(p, err, sw.werrs); err != nil {
+					return err
+				}
+				continue
+			}
+			d = fs.FileInfoToDirEntry(i)
+		}
+
+		if !d.IsDir() {
+			if w.currentPattern().Match(p) {
+				sw.entries = append(sw.entries, pattern.Entry{Path: p, DirEntry: d})
+			}
+			continue
+		}
+
+		if !w.canDescend(p) {
+			continue
+		}
+
+		i, err := d.Info()
+		if err != nil {
+			if err := w.handleWalkError(p, err, sw.werrs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if key, ok := fileKey(i); ok {
+			if _, ok := sw.visited[key]; ok {
+				sw.werrs.errs = append(sw.werrs.errs, fmt.Errorf("%w: %s", ErrSymlinkCycle, p))
+				continue
+			}
+		}
+
+		if err := w.walkFollow(ctx, sw, p, i, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package globwatch
+
+import (
+	"io/fs"
+)
+
+// fileKey reports false as files cannot be identified on this platform.
+// Symlink cycles are only stopped by the maximum symlink depth.
+func fileKey(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
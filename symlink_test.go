@@ -0,0 +1,160 @@
+package globwatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+)
+
+// symlink creates a symlink at newname pointing to oldname. It skips the
+// test if the platform does not support symlinks.
+func symlink(t *testing.T, oldname, newname string) {
+	t.Helper()
+
+	if err := os.Symlink(oldname, newname); err != nil {
+		t.Skipf("symlinks not supported: %s", err)
+	}
+}
+
+func TestWatcher_WithFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(target, "config.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(target, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "docs", "readme.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	symlink(t, filepath.Join(target, "config.txt"), filepath.Join(dir, "config.txt"))
+	symlink(t, filepath.Join(target, "docs"), filepath.Join(dir, "docs"))
+	// A cycle which must not be followed
+	symlink(t, dir, filepath.Join(dir, "docs", "loop"))
+
+	watcher, err := New(os.DirFS(dir), "**/*.txt", time.Second, WithFollowSymlinks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evts, err := watcher.determineInitialState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "config.txt"},
+		{Type: Created, Path: "docs/readme.txt"},
+	}))
+
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(target, "config.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(target, "docs", "readme.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+	close(watcher.c)
+
+	evts = evts[:0]
+	for evt := range watcher.c {
+		evts = append(evts, evt)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Modified, Path: "config.txt"},
+		{Type: Modified, Path: "docs/readme.txt"},
+	}))
+}
+
+func TestWatcher_WithFollowSymlinks_cycle(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "b", "main.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	symlink(t, filepath.Join(dir, "a"), filepath.Join(dir, "a", "b", "up"))
+
+	watcher, err := New(os.DirFS(dir), "**/*.go", time.Second, WithFollowSymlinks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evts, err := watcher.determineInitialState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "a/b/main.go"},
+	}))
+
+	close(watcher.errors)
+
+	errs := make([]error, 0, 1)
+	for err := range watcher.errors {
+		errs = append(errs, err)
+	}
+
+	ExpectThat(t, len(errs)).Is(Equal(1))
+	ExpectThat(t, errs[0]).Is(Error(ErrSymlinkCycle))
+	ExpectThat(t, errs[0].Error()).Is(StringContaining("a/b/up"))
+}
+
+func TestWatcher_WithMaxSymlinkDepth(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(dir, "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "main.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	symlink(t, filepath.Join(dir, "a"), filepath.Join(dir, "b"))
+	symlink(t, filepath.Join(dir, "a", "main.go"), filepath.Join(dir, "b.go"))
+
+	watcher, err := New(os.DirFS(dir), "**/*.go", time.Second, WithFollowSymlinks(true), WithMaxSymlinkDepth(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evts, err := watcher.determineInitialState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+		{Type: Created, Path: "a/main.go"},
+	}))
+
+	close(watcher.errors)
+
+	errs := make([]error, 0, 2)
+	for err := range watcher.errors {
+		errs = append(errs, err)
+	}
+
+	ExpectThat(t, len(errs)).Is(Equal(2))
+	ExpectThat(t, errs[0]).Is(Error(ErrSymlinkCycle))
+	ExpectThat(t, errs[1]).Is(Error(ErrSymlinkCycle))
+}
+
+func TestWithMaxSymlinkDepth_negative(t *testing.T) {
+	_, err := New(os.DirFS("."), "**/*.go", time.Second, WithMaxSymlinkDepth(-1))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package globwatch
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileKey returns a key identifying the file described by info based on its
+// device and inode number.
+func fileKey(info fs.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+
+	return uint64(st.Ino) | uint64(st.Dev)<<32, true
+}
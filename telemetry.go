@@ -0,0 +1,33 @@
+package globwatch
+
+import (
+	"time"
+)
+
+// Telemetry receives metrics collected by a Watcher during change detection.
+// Implementations must be safe for concurrent use as a Telemetry may be
+// shared between multiple watchers. See the separate module
+// github.com/halimath/globwatch/telemetry/prometheus for an implementation
+// exporting Prometheus metrics.
+type Telemetry interface {
+	// RecordPollDuration records the duration of a single change detection.
+	RecordPollDuration(d time.Duration)
+	// RecordEventsEmitted records the number of events a single change
+	// detection produced.
+	RecordEventsEmitted(n int)
+	// RecordErrors records the number of errors reported by a single change
+	// detection.
+	RecordErrors(n int)
+	// RecordFilesScanned records the number of files a single change
+	// detection scanned.
+	RecordFilesScanned(n int)
+}
+
+// NopTelemetry is a Telemetry that discards all metrics. It is used unless
+// another Telemetry is set with WithTelemetry.
+type NopTelemetry struct{}
+
+func (NopTelemetry) RecordPollDuration(time.Duration) {}
+func (NopTelemetry) RecordEventsEmitted(int)          {}
+func (NopTelemetry) RecordErrors(int)                 {}
+func (NopTelemetry) RecordFilesScanned(int)           {}
@@ -0,0 +1,92 @@
+// Package prometheus provides a globwatch.Telemetry exporting Prometheus
+// metrics. It is provided as a separate module so that users of globwatch
+// do not depend on the Prometheus client library.
+//
+// Register a Telemetry with a prometheus.Registerer and pass it to the
+// watcher using globwatch.WithTelemetry (prom refers to the
+// github.com/prometheus/client_golang/prometheus package):
+//
+//	t, err := prometheus.New(prom.DefaultRegisterer, prom.Labels{"watcher": "assets"})
+//	if err != nil {
+//		// ...
+//	}
+//
+//	watcher, err := globwatch.New(fsys, "**/*.go", time.Second, globwatch.WithTelemetry(t))
+package prometheus
+
+import (
+	"time"
+
+	"github.com/halimath/globwatch"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Telemetry implements globwatch.Telemetry by exporting the following
+// metrics:
+//
+//   - globwatch_poll_duration_seconds: histogram of change detection durations
+//   - globwatch_events_emitted_total: counter of emitted events
+//   - globwatch_errors_total: counter of reported errors
+//   - globwatch_files_scanned_total: counter of scanned files
+type Telemetry struct {
+	pollDuration prometheus.Histogram
+	events       prometheus.Counter
+	errors       prometheus.Counter
+	files        prometheus.Counter
+}
+
+var _ globwatch.Telemetry = &Telemetry{}
+
+// New creates a new Telemetry and registers its metrics with reg. labels are
+// attached to all metrics as constant labels; use them to distinguish
+// multiple watchers registered with the same registerer. New returns an
+// error if registering any metric fails.
+func New(reg prometheus.Registerer, labels prometheus.Labels) (*Telemetry, error) {
+	t := &Telemetry{
+		pollDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "globwatch_poll_duration_seconds",
+			Help:        "Duration of a single change detection.",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}),
+		events: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "globwatch_events_emitted_total",
+			Help:        "Number of events emitted.",
+			ConstLabels: labels,
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "globwatch_errors_total",
+			Help:        "Number of errors reported during change detection.",
+			ConstLabels: labels,
+		}),
+		files: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "globwatch_files_scanned_total",
+			Help:        "Number of files scanned during change detection.",
+			ConstLabels: labels,
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{t.pollDuration, t.events, t.errors, t.files} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *Telemetry) RecordPollDuration(d time.Duration) {
+	t.pollDuration.Observe(d.Seconds())
+}
+
+func (t *Telemetry) RecordEventsEmitted(n int) {
+	t.events.Add(float64(n))
+}
+
+func (t *Telemetry) RecordErrors(n int) {
+	t.errors.Add(float64(n))
+}
+
+func (t *Telemetry) RecordFilesScanned(n int) {
+	t.files.Add(float64(n))
+}
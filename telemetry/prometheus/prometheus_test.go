@@ -0,0 +1,37 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestTelemetry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	tel, err := New(reg, prometheus.Labels{"watcher": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.RecordPollDuration(10 * time.Millisecond)
+	tel.RecordEventsEmitted(2)
+	tel.RecordEventsEmitted(1)
+	tel.RecordErrors(1)
+	tel.RecordFilesScanned(5)
+
+	ExpectThat(t, testutil.ToFloat64(tel.events)).Is(Equal(3.0))
+	ExpectThat(t, testutil.ToFloat64(tel.errors)).Is(Equal(1.0))
+	ExpectThat(t, testutil.ToFloat64(tel.files)).Is(Equal(5.0))
+
+	n, err := testutil.GatherAndCount(reg, "globwatch_poll_duration_seconds")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, n).Is(Equal(1))
+
+	_, err = New(reg, prometheus.Labels{"watcher": "test"})
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,124 @@
+package globwatch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"strings"
+
+	"github.com/halimath/globwatch/pattern"
+)
+
+// walkErrors collects the non-fatal errors encountered while walking the
+// watched filesystem along with the directories that could not be read.
+type walkErrors struct {
+	errs []error
+	dirs []string
+}
+
+// skipped reports whether the file p is contained in a directory that could
+// not be read.
+func (we *walkErrors) skipped(p string) bool {
+	for _, d := range we.dirs {
+		if d == "." || strings.HasPrefix(p, d+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// glob returns entries for all files matching w's pattern. Non-fatal errors
+// do not terminate the walk; they are returned along with the directories
+// that could not be read. If dryRun is set, the directories seen are not
+// recorded for auto discovery.
+func (w *Watcher) glob(ctx context.Context, dryRun bool) ([]pattern.Entry, *walkErrors, error) {
+	w.beginDiscovery(dryRun)
+
+	entries, werrs, err := w.walk(ctx)
+
+	w.endDiscovery(err == nil)
+
+	return entries, werrs, err
+}
+
+// walk implements glob.
+func (w *Watcher) walk(ctx context.Context) ([]pattern.Entry, *walkErrors, error) {
+	werrs := &walkErrors{}
+
+	if w.followSymlinks {
+		entries, err := w.globFollow(ctx, werrs)
+		return entries, werrs, err
+	}
+
+	if w.incremental {
+		entries, err := w.scanIncremental(ctx, werrs)
+		return entries, werrs, err
+	}
+
+	entries := make([]pattern.Entry, 0)
+	err := fs.WalkDir(w.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err != nil {
+			return w.handleWalkError(p, err, werrs)
+		}
+
+		if d.IsDir() {
+			if p != "." && !w.canDescend(p) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if w.currentPattern().Match(p) {
+			entries = append(entries, pattern.Entry{Path: p, DirEntry: d})
+		}
+
+		return nil
+	})
+
+	return entries, werrs, err
+}
+
+// canDescend reports whether a walk descends into the directory dir which
+// must not be the root. The walk skips dir if dir exceeds the walk depth set
+// with WithWalkDepth and has not been discovered (see WithAutoDiscoverDepth)
+// or if dir cannot contain files matching w's pattern.
+func (w *Watcher) canDescend(dir string) bool {
+	return (w.withinWalkDepth(dir) || w.discoverDir(dir)) && w.currentPattern().CanDescend(dir)
+}
+
+// withinWalkDepth reports whether the files contained in the directory dir
+// are within the walk depth set with WithWalkDepth.
+func (w *Watcher) withinWalkDepth(dir string) bool {
+	return w.walkDepth <= 0 || strings.Count(dir, "/")+1 <= w.walkDepth
+}
+
+// handleWalkError handles err encountered while reading directory dir. It
+// returns err if err is fatal. Otherwise, err is recorded in werrs and nil is
+// returned so that the walk continues.
+func (w *Watcher) handleWalkError(dir string, err error, werrs *walkErrors) error {
+	if w.isFatal(err) {
+		return err
+	}
+
+	w.log(slog.LevelWarn, "skipping directory", slog.String("path", dir), slog.Any("error", err))
+
+	werrs.errs = append(werrs.errs, err)
+	werrs.dirs = append(werrs.dirs, dir)
+
+	return nil
+}
+
+// isFatal reports whether err terminates a walk. See WithFatalErrorPredicate.
+func (w *Watcher) isFatal(err error) bool {
+	if w.fatalError != nil {
+		return w.fatalError(err)
+	}
+
+	return !errors.Is(err, fs.ErrPermission) && !errors.Is(err, fs.ErrNotExist)
+}
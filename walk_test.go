@@ -0,0 +1,302 @@
+package globwatch
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+
+	. "github.com/halimath/expect-go"
+)
+
+// deniedFS wraps a fsmock.FS and denies access to a single directory and all
+// of its contents.
+type deniedFS struct {
+	*fsmock.FS
+	denied string
+}
+
+func (d *deniedFS) check(op, name string) error {
+	if d.denied != "" && (name == d.denied || strings.HasPrefix(name, d.denied+"/")) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+	}
+	return nil
+}
+
+func (d *deniedFS) Open(name string) (fs.File, error) {
+	if err := d.check("open", name); err != nil {
+		return nil, err
+	}
+	return d.FS.Open(name)
+}
+
+func (d *deniedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := d.check("readdir", name); err != nil {
+		return nil, err
+	}
+	return d.FS.ReadDir(name)
+}
+
+func (d *deniedFS) Stat(name string) (fs.FileInfo, error) {
+	if err := d.check("stat", name); err != nil {
+		return nil, err
+	}
+	return d.FS.Stat(name)
+}
+
+func TestWatcher_nonFatalErrors(t *testing.T) {
+	for name, incremental := range map[string]bool{"full": false, "incremental": true} {
+		t.Run(name, func(t *testing.T) {
+			fsys := &deniedFS{
+				FS: fsmock.New(fsmock.NewDir("",
+					fsmock.NewDir("cmd",
+						fsmock.TextFile("main.go", "package main"),
+					),
+					fsmock.NewDir("secret",
+						fsmock.TextFile("key.go", "package secret"),
+					),
+				)),
+			}
+
+			watcher, err := New(fsys, "**/*.go", time.Second, WithIncrementalScan(incremental))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := watcher.determineInitialState(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			fsys.denied = "secret"
+			fsys.Touch("cmd/main.go")
+			fsys.Touch("cmd/main_test.go")
+			fsys.FS.Touch("cmd")
+
+			ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+			ExpectThat(t, <-watcher.errors).Is(Error(fs.ErrPermission))
+
+			close(watcher.c)
+			evts := make([]Event, 0, 20)
+			for evt := range watcher.c {
+				evts = append(evts, evt)
+			}
+
+			// Files in the denied directory are not reported as deleted.
+			ExpectThat(t, withoutInfo(evts)).Is(DeepEqual([]Event{
+				{Type: Created, Path: "cmd/main_test.go"},
+				{Type: Modified, Path: "cmd/main.go"},
+			}))
+			ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"cmd/main.go", "cmd/main_test.go", "secret/key.go"}))
+		})
+	}
+}
+
+func TestWatcher_WithFatalErrorPredicate(t *testing.T) {
+	fsys := &deniedFS{
+		FS: fsmock.New(fsmock.NewDir("",
+			fsmock.NewDir("cmd",
+				fsmock.TextFile("main.go", "package main"),
+			),
+			fsmock.NewDir("secret",
+				fsmock.TextFile("key.go", "package secret"),
+			),
+		)),
+		denied: "secret",
+	}
+
+	watcher, err := New(fsys, "**/*.go", time.Second, WithFatalErrorPredicate(func(error) bool { return true }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = watcher.determineInitialState(context.Background())
+	ExpectThat(t, err).Is(Error(fs.ErrPermission))
+
+	fsys.denied = ""
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.denied = "secret"
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(Error(fs.ErrPermission))
+	ExpectThat(t, <-watcher.errors).Is(Error(fs.ErrPermission))
+	ExpectThat(t, len(watcher.c)).Is(Equal(0))
+}
+
+// switchFS wraps a fsmock.FS and fails to access any file once fail is set.
+type switchFS struct {
+	*fsmock.FS
+	fail atomic.Bool
+}
+
+func (s *switchFS) Open(name string) (fs.File, error) {
+	if s.fail.Load() {
+		return failingFS{}.Open(name)
+	}
+	return s.FS.Open(name)
+}
+
+func (s *switchFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if s.fail.Load() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errIO}
+	}
+	return s.FS.ReadDir(name)
+}
+
+func (s *switchFS) Stat(name string) (fs.FileInfo, error) {
+	if s.fail.Load() {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: errIO}
+	}
+	return s.FS.Stat(name)
+}
+
+func TestWatcher_WithMaxConsecutiveErrors(t *testing.T) {
+	fsys := &switchFS{
+		FS: fsmock.New(fsmock.NewDir("",
+			fsmock.TextFile("main.go", "package main"),
+		)),
+	}
+
+	watcher, err := New(fsys, "**/*.go", time.Millisecond, WithMaxConsecutiveErrors(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.fail.Store(true)
+
+	var errs []error
+	for err := range watcher.ErrorsChan() {
+		errs = append(errs, err)
+	}
+	<-watcher.closed
+
+	ExpectThat(t, len(errs)).Is(Equal(4))
+	for _, err := range errs {
+		ExpectThat(t, err).Is(Error(errIO))
+	}
+	ExpectThat(t, errs[3]).Is(Error(ErrMaxErrorsExceeded))
+	ExpectThat(t, watcher.IsRunning()).Is(Equal(false))
+
+	_, ok := <-watcher.C()
+	ExpectThat(t, ok).Is(Equal(false))
+
+	// Closing a watcher that stopped by itself is safe.
+	watcher.Close()
+}
+
+func TestWatcher_failed(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir(""))
+
+	watcher, err := New(fsys, "**/*.go", time.Millisecond, WithMaxConsecutiveErrors(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.ctx = context.Background()
+
+	// A successful poll resets the count.
+	ExpectThat(t, watcher.failed(errIO)).Is(Equal(false))
+	ExpectThat(t, watcher.failed(nil)).Is(Equal(false))
+	ExpectThat(t, watcher.failed(errIO)).Is(Equal(false))
+	ExpectThat(t, watcher.failed(errIO)).Is(Equal(true))
+	ExpectThat(t, <-watcher.errors).Is(Error(ErrMaxErrorsExceeded))
+}
+
+func TestWatcher_WithWalkDepth(t *testing.T) {
+	for name, incremental := range map[string]bool{"full": false, "incremental": true} {
+		t.Run(name, func(t *testing.T) {
+			fsys := fsmock.New(fsmock.NewDir("",
+				fsmock.EmptyFile("main.go"),
+				fsmock.NewDir("a",
+					fsmock.EmptyFile("a.go"),
+					fsmock.NewDir("b",
+						fsmock.EmptyFile("b.go"),
+						fsmock.EmptyFile("README.md"),
+						fsmock.NewDir("c",
+							fsmock.EmptyFile("c.go"),
+						),
+					),
+				),
+			))
+
+			watcher, err := New(fsys, "**/*.go", time.Second, WithWalkDepth(2), WithIncrementalScan(incremental))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := watcher.determineInitialState(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"a/a.go", "a/b/b.go", "main.go"}))
+		})
+	}
+
+	_, err := New(fsmock.New(fsmock.NewDir("")), "**/*.go", time.Second, WithWalkDepth(-1))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+}
+
+func TestWatcher_WithAutoDiscoverDepth(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.EmptyFile("main.go"),
+		fsmock.NewDir("a",
+			fsmock.EmptyFile("a.go"),
+			fsmock.NewDir("old",
+				fsmock.EmptyFile("old.go"),
+			),
+		),
+	))
+
+	watcher, err := New(fsys, "**/*.go", time.Second, WithWalkDepth(1), WithAutoDiscoverDepth(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watcher.determineInitialState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"a/a.go", "main.go"}))
+
+	// Create a directory beyond the walk depth along with its files.
+	fsys.Mkdir("a/new")
+	fsys.Touch("a/new/new.go")
+	fsys.Mkdir("a/new/b")
+	fsys.Touch("a/new/b/b.go")
+	fsys.Mkdir("a/new/b/c")
+	fsys.Touch("a/new/b/c/c.go")
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+	ExpectThat(t, (<-watcher.C()).Path).Is(Equal("a/new/b/b.go"))
+	ExpectThat(t, (<-watcher.C()).Path).Is(Equal("a/new/new.go"))
+	ExpectThat(t, len(watcher.C())).Is(Equal(0))
+
+	// Discovered directories are walked during subsequent scans.
+	fsys.Touch("a/new/new.go")
+	fsys.Touch("a/old/old.go")
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+
+	evt := <-watcher.C()
+	ExpectThat(t, Event{Type: evt.Type, Path: evt.Path}).Is(DeepEqual(Event{Type: Modified, Path: "a/new/new.go"}))
+	ExpectThat(t, len(watcher.C())).Is(Equal(0))
+
+	fsys.Rm("a/new")
+
+	ExpectThat(t, watcher.detectChanges(context.Background())).Is(NoError())
+	ExpectThat(t, (<-watcher.C()).Type).Is(Equal(Deleted))
+	ExpectThat(t, (<-watcher.C()).Type).Is(Equal(Deleted))
+	ExpectThat(t, watcher.Files()).Is(DeepEqual([]string{"a/a.go", "main.go"}))
+
+	_, err = New(fsmock.New(fsmock.NewDir("")), "**/*.go", time.Second, WithAutoDiscoverDepth(-1))
+	ExpectThat(t, err).Is(Error(ErrInvalidOption))
+}
@@ -0,0 +1,183 @@
+package globwatch
+
+import (
+	"sort"
+	"sync"
+)
+
+// NamedEvent is an Event received from a Watcher registered with a
+// WatchList tagged with the name the Watcher has been registered under.
+type NamedEvent struct {
+	Name  string
+	Event Event
+}
+
+// WatchList manages a set of Watchers each registered under a unique name.
+// Unlike Pool, a WatchList does not create or start Watchers; callers add
+// Watchers they have configured and started themselves. The events of all
+// registered Watchers are merged into a single channel. Errors are not
+// merged; consume each Watcher's errors channel or use callbacks.
+//
+// All methods of WatchList are safe for concurrent use.
+type WatchList struct {
+	mu       sync.RWMutex
+	watchers map[string]*watchListEntry
+	closed   bool
+
+	c  chan NamedEvent
+	wg sync.WaitGroup
+}
+
+// watchListEntry holds a Watcher registered with a WatchList along with the
+// channels used to stop forwarding its events.
+type watchListEntry struct {
+	w    *Watcher
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWatchList creates a new, empty WatchList.
+func NewWatchList() *WatchList {
+	return &WatchList{
+		watchers: make(map[string]*watchListEntry),
+		c:        make(chan NamedEvent, 10),
+	}
+}
+
+// Add registers w under name and starts forwarding w's events to
+// MergedEvents. It returns ErrDuplicate if a Watcher has already been
+// registered under name and ErrClosed if l has been stopped.
+func (l *WatchList) Add(name string, w *Watcher) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	if _, ok := l.watchers[name]; ok {
+		return ErrDuplicate
+	}
+
+	e := &watchListEntry{
+		w:    w,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	l.watchers[name] = e
+
+	l.wg.Add(1)
+	go l.forward(name, e)
+
+	return nil
+}
+
+// Get returns the Watcher registered under name.
+func (l *WatchList) Get(name string) (*Watcher, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	e, ok := l.watchers[name]
+	if !ok {
+		return nil, false
+	}
+
+	return e.w, true
+}
+
+// Remove unregisters and closes the Watcher registered under name. Once
+// Remove returns, no more events from that Watcher are sent to
+// MergedEvents. It returns ErrNotFound if no Watcher has been registered
+// under name.
+func (l *WatchList) Remove(name string) error {
+	l.mu.Lock()
+	e, ok := l.watchers[name]
+	delete(l.watchers, name)
+	l.mu.Unlock()
+
+	if !ok {
+		return ErrNotFound
+	}
+
+	l.stop(e)
+
+	return nil
+}
+
+// Names returns the sorted names of all registered Watchers.
+func (l *WatchList) Names() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	names := make([]string, 0, len(l.watchers))
+	for name := range l.watchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// MergedEvents returns a channel used to receive the events of all
+// registered Watchers. The channel is closed by StopAll.
+func (l *WatchList) MergedEvents() <-chan NamedEvent {
+	return l.c
+}
+
+// StopAll unregisters and closes all Watchers and closes the channel
+// returned from MergedEvents. Adding a Watcher afterwards returns ErrClosed.
+func (l *WatchList) StopAll() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.closed = true
+	watchers := l.watchers
+	l.watchers = make(map[string]*watchListEntry)
+	l.mu.Unlock()
+
+	for _, e := range watchers {
+		l.stop(e)
+	}
+
+	l.wg.Wait()
+	close(l.c)
+}
+
+// stop stops forwarding e's events and closes e's Watcher if it is running.
+func (l *WatchList) stop(e *watchListEntry) {
+	close(e.stop)
+	<-e.done
+
+	if e.w.IsRunning() {
+		e.w.Close()
+	}
+}
+
+// forward forwards the events of e's Watcher tagged with name until the
+// Watcher's channel is closed or e is stopped.
+func (l *WatchList) forward(name string, e *watchListEntry) {
+	defer l.wg.Done()
+	defer close(e.done)
+
+	c := e.w.C()
+
+	for {
+		select {
+		case evt, ok := <-c:
+			if !ok {
+				return
+			}
+
+			select {
+			case l.c <- NamedEvent{Name: name, Event: evt}:
+			case <-e.stop:
+				return
+			}
+
+		case <-e.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package globwatch_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/halimath/fsmock"
+	"github.com/halimath/globwatch"
+
+	. "github.com/halimath/expect-go"
+)
+
+func TestWatchList(t *testing.T) {
+	src := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+	docs := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("README.md", "# README"),
+	))
+
+	list := globwatch.NewWatchList()
+	defer list.StopAll()
+
+	for name, fsys := range map[string]*fsmock.FS{"src": src, "docs": docs} {
+		w, err := globwatch.New(fsys, "**/*", time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Start(); err != nil {
+			t.Fatal(err)
+		}
+		ExpectThat(t, list.Add(name, w)).Is(NoError())
+		ExpectThat(t, list.Add(name, w)).Is(Error(globwatch.ErrDuplicate))
+	}
+
+	ExpectThat(t, list.Names()).Is(DeepEqual([]string{"docs", "src"}))
+
+	w, ok := list.Get("src")
+	ExpectThat(t, ok).Is(Equal(true))
+	ExpectThat(t, w.IsRunning()).Is(Equal(true))
+
+	src.Touch("main_test.go")
+	evt := <-list.MergedEvents()
+	ExpectThat(t, evt.Name).Is(Equal("src"))
+	ExpectThat(t, evt.Event.Type).Is(Equal(globwatch.Created))
+	ExpectThat(t, evt.Event.Path).Is(Equal("main_test.go"))
+
+	ExpectThat(t, list.Remove("src")).Is(NoError())
+	ExpectThat(t, list.Remove("src")).Is(Error(globwatch.ErrNotFound))
+	ExpectThat(t, w.IsRunning()).Is(Equal(false))
+
+	_, ok = list.Get("src")
+	ExpectThat(t, ok).Is(Equal(false))
+
+	src.Touch("tool.go")
+	docs.Touch("CHANGELOG.md")
+
+	evt = <-list.MergedEvents()
+	ExpectThat(t, evt.Name).Is(Equal("docs"))
+	ExpectThat(t, evt.Event.Path).Is(Equal("CHANGELOG.md"))
+
+	select {
+	case evt := <-list.MergedEvents():
+		t.Errorf("unexpected event: %v", evt)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWatchList_StopAll(t *testing.T) {
+	fsys := fsmock.New(fsmock.NewDir("",
+		fsmock.TextFile("main.go", "package main"),
+	))
+
+	w, err := globwatch.New(fsys, "**/*", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	list := globwatch.NewWatchList()
+	ExpectThat(t, list.Add("src", w)).Is(NoError())
+
+	list.StopAll()
+
+	ExpectThat(t, w.IsRunning()).Is(Equal(false))
+	ExpectThat(t, list.Names()).Is(DeepEqual([]string{}))
+	ExpectThat(t, list.Add("src", w)).Is(Error(globwatch.ErrClosed))
+
+	_, ok := <-list.MergedEvents()
+	ExpectThat(t, ok).Is(Equal(false))
+}
+
+func TestWatchList_concurrent(t *testing.T) {
+	list := globwatch.NewWatchList()
+	defer list.StopAll()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			w, err := globwatch.New(fsmock.New(fsmock.NewDir("")), "**/*", time.Millisecond)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			name := fmt.Sprintf("watcher-%d", i)
+			if err := list.Add(name, w); err != nil {
+				t.Error(err)
+				return
+			}
+
+			list.Names()
+
+			if i%2 == 0 {
+				if err := list.Remove(name); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ExpectThat(t, list.Names()).Is(DeepEqual([]string{"watcher-1", "watcher-3", "watcher-5", "watcher-7", "watcher-9"}))
+}